@@ -0,0 +1,131 @@
+// Utility to purge files/directories moved into a quarantine directory using
+// prune --move-to
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/codezombiech/prune/retention"
+	flag "github.com/spf13/pflag"
+)
+
+// ExitCodePurgeFailed is used when at least one quarantine entry could not be purged
+const ExitCodePurgeFailed = 3
+
+// removeAll is used to purge quarantine entries, replaceable for testing
+var removeAll = os.RemoveAll
+
+var (
+	logger *log.Logger
+
+	olderThan string
+	verbose   bool
+	dryRun    bool
+)
+
+type PurgeError struct {
+	Failed int
+	Total  int
+}
+
+func (e *PurgeError) Error() string {
+	return fmt.Sprintf("failed to purge %d of %d quarantine entries", e.Failed, e.Total)
+}
+
+func init() {
+	logger = log.New(os.Stderr, "", 0)
+
+	flag.StringVar(&olderThan, "older-than", "", "purge quarantine entries moved longer ago than a duration, e.g. 7d, 2w, 3m or 1y")
+	flag.BoolVarP(&verbose, "verbose", "v", false, "report purged quarantine entries")
+	flag.BoolVar(&dryRun, "dry-run", false, "report quarantine entries that would be purged without purging them")
+}
+
+func main() {
+	// Parse
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		logger.Printf("Missing argument: provide at least one quarantine directory")
+		os.Exit(2)
+	}
+	if olderThan == "" {
+		logger.Printf("Missing --older-than")
+		os.Exit(2)
+	}
+	age, err := retention.ParseDuration(olderThan)
+	if err != nil {
+		logger.Printf("Invalid --older-than: %v", err)
+		os.Exit(2)
+	}
+
+	// Run
+	cutoff := age.Before(time.Now())
+	failed := false
+	for _, quarantine := range flag.Args() {
+		if err := purge(quarantine, cutoff, dryRun); err != nil {
+			logger.Printf("%v", err)
+			var purgeErr *PurgeError
+			if !errors.As(err, &purgeErr) {
+				os.Exit(1)
+			}
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(ExitCodePurgeFailed)
+	}
+}
+
+// purge removes the entries of quarantine moved before cutoff. prune
+// --move-to sets the modification time of an entry to the time it was moved
+// to the quarantine directory. Purging continues if a single entry cannot be
+// removed and a PurgeError summarising all failures is returned at the end
+func purge(quarantine string, cutoff time.Time, dryRun bool) error {
+	entries, err := os.ReadDir(quarantine)
+	if err != nil {
+		return err
+	}
+
+	paths := []string{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().Before(cutoff) {
+			paths = append(paths, path.Join(quarantine, entry.Name()))
+		}
+	}
+
+	sort.Strings(paths)
+
+	failed := 0
+	for _, entryPath := range paths {
+		if dryRun {
+			logger.Printf("Would purge %s", entryPath)
+			continue
+		}
+
+		if err := removeAll(entryPath); err != nil {
+			logger.Printf("Failed to purge %s: %v", entryPath, err)
+			failed++
+			continue
+		}
+
+		if verbose {
+			logger.Printf("Purged %s", entryPath)
+		}
+	}
+
+	if failed > 0 {
+		return &PurgeError{Failed: failed, Total: len(paths)}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestPurge(t *testing.T) {
+	quarantine := t.TempDir()
+
+	// Arrange
+	now := time.Now()
+	entries := []struct {
+		name           string
+		movedAt        time.Time
+		expectedPurged bool
+	}{
+		{"2000-01-01T00-00-00Z", now.AddDate(0, 0, -10), true},
+		{"2000-01-01T00-00-00Z.1", now.AddDate(0, 0, -8), true},
+		{"2000-01-02T00-00-00Z", now.AddDate(0, 0, -6), false},
+		{"2000-01-03T00-00-00Z", now, false},
+	}
+	for _, entry := range entries {
+		entryPath := path.Join(quarantine, entry.name)
+		if err := os.Mkdir(entryPath, 0755); err != nil {
+			t.Fatalf("Failed to create directory %s", entry.name)
+		}
+		if err := os.WriteFile(path.Join(entryPath, "backup.tar.gz"), []byte(entry.name), 0644); err != nil {
+			t.Fatalf("Failed to create file in %s", entry.name)
+		}
+		if err := os.Chtimes(entryPath, entry.movedAt, entry.movedAt); err != nil {
+			t.Fatalf("Failed to set time of %s", entry.name)
+		}
+	}
+
+	// Act
+	err := purge(quarantine, now.AddDate(0, 0, -7), false)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	for _, entry := range entries {
+		if _, err := os.Stat(path.Join(quarantine, entry.name)); (err != nil) != entry.expectedPurged {
+			t.Errorf("%v: Expected purged %v, got %v", entry.name, entry.expectedPurged, err)
+		}
+	}
+}
+
+func TestPurgeDryRun(t *testing.T) {
+	quarantine := t.TempDir()
+
+	// Arrange
+	entryPath := path.Join(quarantine, "2000-01-01T00-00-00Z")
+	if err := os.Mkdir(entryPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory %s", entryPath)
+	}
+	movedAt := time.Now().AddDate(-1, 0, 0)
+	if err := os.Chtimes(entryPath, movedAt, movedAt); err != nil {
+		t.Fatalf("Failed to set time of %s", entryPath)
+	}
+
+	// Act
+	err := purge(quarantine, time.Now(), true)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	if _, err := os.Stat(entryPath); err != nil {
+		t.Errorf("Expected %v to be present, got %v", entryPath, err)
+	}
+}
+
+func TestPurgeContinuesOnFailure(t *testing.T) {
+	quarantine := t.TempDir()
+
+	// Arrange
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.Mkdir(path.Join(quarantine, name), 0755); err != nil {
+			t.Fatalf("Failed to create directory %s", name)
+		}
+	}
+
+	purged := []string{}
+	removeAll = func(entryPath string) error {
+		if path.Base(entryPath) == "b" {
+			return os.ErrPermission
+		}
+		purged = append(purged, entryPath)
+		return nil
+	}
+	t.Cleanup(func() { removeAll = os.RemoveAll })
+
+	// Act
+	err := purge(quarantine, time.Now().Add(time.Hour), false)
+
+	// Assert
+	var purgeErr *PurgeError
+	if !errors.As(err, &purgeErr) {
+		t.Fatalf("Expected error of type PurgeError, got %v", err)
+	}
+	if expected, actual := 1, purgeErr.Failed; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 2, len(purged); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
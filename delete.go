@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/codezombiech/prune/retention"
+)
+
+// ExitCodeDeleteFailed is used when at least one file/directory to prune could not be deleted (or moved, see --move-to)
+const ExitCodeDeleteFailed = 3
+
+// removeAll is used to delete files/directories, replaceable for testing
+var removeAll = os.RemoveAll
+
+type DeleteError struct {
+	Failed int
+	Total  int
+}
+
+func (e *DeleteError) Error() string {
+	return fmt.Sprintf("failed to delete %d of %d files/directories", e.Failed, e.Total)
+}
+
+// deleteObjects deletes all candidates to prune. Deletion continues if a
+// single candidate cannot be deleted and a DeleteError summarising all
+// failures is returned at the end
+func deleteObjects(objects []retention.PruneCandidate, dryRun bool) error {
+	paths := make([]string, 0, len(objects))
+	for _, object := range objects {
+		paths = append(paths, object.Object.Path)
+	}
+
+	sort.Strings(paths)
+
+	failed := 0
+	for _, path := range paths {
+		if dryRun {
+			errorLogger.Printf("Would delete %s", path)
+			continue
+		}
+
+		if err := removeAll(path); err != nil {
+			errorLogger.Printf("Failed to delete %s: %v", path, err)
+			failed++
+			continue
+		}
+
+		if verbose {
+			errorLogger.Printf("Deleted %s", path)
+		}
+	}
+
+	if failed > 0 {
+		return &DeleteError{Failed: failed, Total: len(paths)}
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/codezombiech/prune/retention"
+)
+
+func TestDeleteObjects(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	names := []string{"2000-01-01T00-00-00Z", "2000-01-02T00-00-00Z"}
+	objects := []retention.PruneCandidate{}
+	for _, name := range names {
+		directoryPath := path.Join(rootDir, name)
+		if err := os.Mkdir(directoryPath, 0755); err != nil {
+			t.Fatalf("Failed to create directory %s", name)
+		}
+		objects = append(objects, retention.PruneCandidate{Object: retention.TimeStampedObject{Name: name, Path: directoryPath}})
+	}
+
+	// Act
+	err := deleteObjects(objects, false)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to delete objects: %v", err)
+	}
+	for _, name := range names {
+		if dirExists(path.Join(rootDir, name)) {
+			t.Errorf("Expected %v to be deleted", name)
+		}
+	}
+}
+
+func TestDeleteObjectsDryRun(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	directoryPath := path.Join(rootDir, "2000-01-01T00-00-00Z")
+	if err := os.Mkdir(directoryPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory %s", directoryPath)
+	}
+	objects := []retention.PruneCandidate{{Object: retention.TimeStampedObject{Path: directoryPath}}}
+
+	// Act
+	err := deleteObjects(objects, true)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to delete objects: %v", err)
+	}
+	if !dirExists(directoryPath) {
+		t.Errorf("Expected %v to exist", directoryPath)
+	}
+}
+
+func TestDeleteObjectsContinuesOnFailure(t *testing.T) {
+	// Arrange
+	objects := []retention.PruneCandidate{
+		{Object: retention.TimeStampedObject{Path: "/foo/bar/a"}},
+		{Object: retention.TimeStampedObject{Path: "/foo/bar/b"}},
+		{Object: retention.TimeStampedObject{Path: "/foo/bar/c"}},
+	}
+
+	deleted := []string{}
+	removeAll = func(path string) error {
+		if path == "/foo/bar/b" {
+			return os.ErrPermission
+		}
+		deleted = append(deleted, path)
+		return nil
+	}
+	t.Cleanup(func() { removeAll = os.RemoveAll })
+
+	// Act
+	err := deleteObjects(objects, false)
+
+	// Assert
+	var deleteErr *DeleteError
+	if !errors.As(err, &deleteErr) {
+		t.Fatalf("Expected error of type DeleteError, got %v", err)
+	}
+	if expected, actual := 1, deleteErr.Failed; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 3, deleteErr.Total; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 2, len(deleted); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func dirExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
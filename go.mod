@@ -5,4 +5,5 @@ go 1.17
 require (
 	github.com/itchyny/timefmt-go v0.1.3
 	github.com/spf13/pflag v1.0.5
+	gopkg.in/yaml.v3 v3.0.1
 )
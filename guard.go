@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/codezombiech/prune/retention"
+)
+
+// ExitCodeGuardFailed is used when a safety guard refuses to prune the files/directories of a job
+const ExitCodeGuardFailed = 4
+
+// Guard defines safety checks applied to the result of a job before anything
+// is output or deleted
+type Guard struct {
+	MaxPrunePercent  float64 // Maximum percentage of files/directories to prune
+	MinKeep          int     // Minimum number of files/directories to keep
+	AllowPruneNewest bool    // Allow pruning the newest file/directory of a series
+}
+
+type GuardError struct {
+	Job    string
+	Reason string
+}
+
+func (e *GuardError) Error() string {
+	if e.Job != "" {
+		return fmt.Sprintf("job %s: refusing to prune, %s (use --force to prune anyway)", e.Job, e.Reason)
+	}
+	return fmt.Sprintf("refusing to prune, %s (use --force to prune anyway)", e.Reason)
+}
+
+// check returns a GuardError if result prunes the newest file/directory of a
+// series (unless allowed) or violates the thresholds of the guard
+func (g Guard) check(job string, result retention.PruneResult) error {
+	if newest, ok := newestPruned(result); ok && !g.AllowPruneNewest {
+		return &GuardError{Job: job, Reason: fmt.Sprintf("the newest file/directory %s would be pruned", newest.Object.Path)}
+	}
+
+	total := len(result.ToKeep) + len(result.ToPrune)
+	if prune := len(result.ToPrune); float64(prune)*100 > g.MaxPrunePercent*float64(total) {
+		return &GuardError{Job: job, Reason: fmt.Sprintf("%d of %d files/directories (%.0f%%) would be pruned, more than --max-prune-percent %v", prune, total, float64(prune)*100/float64(total), g.MaxPrunePercent)}
+	}
+
+	if keep := len(result.ToKeep); keep < g.MinKeep {
+		return &GuardError{Job: job, Reason: fmt.Sprintf("only %d files/directories would be kept, fewer than --min-keep %d", keep, g.MinKeep)}
+	}
+
+	return nil
+}
+
+// newestPruned returns the newest valid file/directory of a series if it is
+// pruned
+func newestPruned(result retention.PruneResult) (retention.PruneCandidate, bool) {
+	newest := make(map[string]*retention.PruneCandidate)
+	for _, object := range result.Objects {
+		if object.Object.Invalid != "" {
+			continue
+		}
+		series := object.Object.Series
+		if current, ok := newest[series]; !ok || object.Object.Time.After(current.Object.Time) {
+			newest[series] = object
+		}
+	}
+
+	for _, series := range result.Series() {
+		if object, ok := newest[series]; ok && !object.Keep {
+			return *object, true
+		}
+	}
+	return retention.PruneCandidate{}, false
+}
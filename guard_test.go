@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/codezombiech/prune/retention"
+)
+
+func TestGuardCheck(t *testing.T) {
+	testCases := []struct {
+		name          string
+		guard         Guard
+		keep          []bool // Oldest first
+		expectedError bool
+	}{
+		{"default", Guard{MaxPrunePercent: 100}, []bool{false, false, true}, false},
+		{"newest pruned", Guard{MaxPrunePercent: 100}, []bool{true, false, false}, true},
+		{"everything pruned", Guard{MaxPrunePercent: 100}, []bool{false, false}, true},
+		{"newest pruned allowed", Guard{MaxPrunePercent: 100, AllowPruneNewest: true}, []bool{true, false, false}, false},
+		{"newest pruned allowed min keep", Guard{MaxPrunePercent: 100, MinKeep: 2, AllowPruneNewest: true}, []bool{true, false, false}, true},
+		{"max prune percent", Guard{MaxPrunePercent: 50}, []bool{false, false, true}, true},
+		{"max prune percent reached", Guard{MaxPrunePercent: 50}, []bool{false, true}, false},
+		{"min keep", Guard{MaxPrunePercent: 100, MinKeep: 2}, []bool{false, false, true}, true},
+		{"min keep reached", Guard{MaxPrunePercent: 100, MinKeep: 2}, []bool{false, true, true}, false},
+		{"empty", Guard{MaxPrunePercent: 0}, []bool{}, false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			result := createPruneResult(testCase.keep)
+
+			// Act
+			err := testCase.guard.check("db", result)
+
+			// Assert
+			var guardErr *GuardError
+			if actual := errors.As(err, &guardErr); actual != testCase.expectedError {
+				t.Errorf("Expected guard error %v, got %v", testCase.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestGuardCheckNewestPerSeries(t *testing.T) {
+	// Arrange
+	result := retention.PruneResult{Objects: map[string]*retention.PruneCandidate{}}
+	for i, candidate := range []retention.PruneCandidate{
+		{Object: retention.TimeStampedObject{Path: "/a-1", Series: "a-", Time: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)}, Keep: true},
+		{Object: retention.TimeStampedObject{Path: "/b-1", Series: "b-", Time: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)}, Keep: false},
+		{Object: retention.TimeStampedObject{Path: "/b-2", Series: "b-", Time: time.Date(1999, 1, 1, 0, 0, 0, 0, time.UTC)}, Keep: true},
+	} {
+		candidate := candidate
+		result.Objects[fmt.Sprint(i)] = &candidate
+		if candidate.Keep {
+			result.ToKeep = append(result.ToKeep, candidate)
+		} else {
+			result.ToPrune = append(result.ToPrune, candidate)
+		}
+	}
+
+	// Act
+	err := Guard{MaxPrunePercent: 100}.check("", result)
+
+	// Assert
+	if err == nil {
+		t.Fatalf("Expected the newest file/directory of series b- to be guarded")
+	}
+	if expected, actual := "refusing to prune, the newest file/directory /b-1 would be pruned (use --force to prune anyway)", err.Error(); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestValidateGuard(t *testing.T) {
+	testCases := []struct {
+		maxPrunePercent float64
+		minKeep         int
+		expectedError   bool
+	}{
+		{100, 0, false},
+		{0, 7, false},
+		{-1, 0, true},
+		{101, 0, true},
+		{50, -1, true},
+	}
+
+	for _, testCase := range testCases {
+		// Act
+		err := validateGuard(testCase.maxPrunePercent, testCase.minKeep)
+
+		// Assert
+		if actual := err != nil; actual != testCase.expectedError {
+			t.Errorf("%v, %v: Expected error %v, got %v", testCase.maxPrunePercent, testCase.minKeep, testCase.expectedError, err)
+		}
+	}
+}
+
+// createPruneResult returns a result with one candidate per day, starting on
+// 2000-01-01, kept according to keep
+func createPruneResult(keep []bool) retention.PruneResult {
+	result := retention.PruneResult{Objects: map[string]*retention.PruneCandidate{}}
+	for i, k := range keep {
+		objectTime := time.Date(2000, 1, 1+i, 0, 0, 0, 0, time.UTC)
+		candidate := retention.PruneCandidate{Object: retention.TimeStampedObject{Path: "/backups/" + objectTime.Format("2006-01-02"), Time: objectTime}, Keep: k}
+		result.Objects[candidate.Object.Path] = &candidate
+		if k {
+			result.ToKeep = append(result.ToKeep, candidate)
+		} else {
+			result.ToPrune = append(result.ToPrune, candidate)
+		}
+	}
+	return result
+}
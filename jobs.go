@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/codezombiech/prune/retention"
+	"gopkg.in/yaml.v3"
+)
+
+// JobFile is the content of the file passed using the --config flag
+type JobFile struct {
+	Jobs []Job `yaml:"jobs"`
+}
+
+// Job is a named set of directories and the retention policy applied to them
+type Job struct {
+	Name        string             `yaml:"name"`
+	Sources     []retention.Source `yaml:"sources"`
+	KeepLast    int                `yaml:"keep-last"`
+	KeepHourly  int                `yaml:"keep-hourly"`
+	KeepDaily   int                `yaml:"keep-daily"`
+	KeepWeekly  int                `yaml:"keep-weekly"`
+	KeepMonthly int                `yaml:"keep-monthly"`
+	KeepYearly  int                `yaml:"keep-yearly"`
+
+	KeepQuarterly  int                 `yaml:"keep-quarterly"`
+	KeepHalfYearly int                 `yaml:"keep-half-yearly"`
+	WeekStart      retention.WeekStart `yaml:"week-start"`
+
+	HourlyPick     retention.Pick `yaml:"hourly-pick"`
+	DailyPick      retention.Pick `yaml:"daily-pick"`
+	WeeklyPick     retention.Pick `yaml:"weekly-pick"`
+	MonthlyPick    retention.Pick `yaml:"monthly-pick"`
+	QuarterlyPick  retention.Pick `yaml:"quarterly-pick"`
+	HalfYearlyPick retention.Pick `yaml:"half-yearly-pick"`
+	YearlyPick     retention.Pick `yaml:"yearly-pick"`
+
+	Strict []string `yaml:"strict"`
+
+	KeepWithin        retention.Duration `yaml:"keep-within"`
+	KeepWithinHourly  retention.Duration `yaml:"keep-within-hourly"`
+	KeepWithinDaily   retention.Duration `yaml:"keep-within-daily"`
+	KeepWithinWeekly  retention.Duration `yaml:"keep-within-weekly"`
+	KeepWithinMonthly retention.Duration `yaml:"keep-within-monthly"`
+	KeepWithinYearly  retention.Duration `yaml:"keep-within-yearly"`
+
+	MinAge retention.Duration `yaml:"min-age"`
+
+	Timezone string `yaml:"timezone"`
+
+	PinMarker string `yaml:"pin-marker"`
+	PinList   string `yaml:"pin-list"`
+
+	RequireGlobs     []string `yaml:"require-globs"`
+	CompletionMarker string   `yaml:"completion-marker"`
+	VerifySHA256Sum  bool     `yaml:"verify-sha256sum"`
+
+	MaxPrunePercent  float64 `yaml:"max-prune-percent"`
+	MinKeep          int     `yaml:"min-keep"`
+	AllowPruneNewest bool    `yaml:"allow-prune-newest"`
+}
+
+// UnmarshalYAML disables all keep rules not defined in the job and uses the
+// default pin marker and guard, aligned with the defaults of the --keep-*,
+// --pin-marker and --max-prune-percent flags
+func (j *Job) UnmarshalYAML(value *yaml.Node) error {
+	type rawJob Job
+	raw := rawJob{
+		KeepLast:    retention.NoPrune,
+		KeepHourly:  retention.NoPrune,
+		KeepDaily:   retention.NoPrune,
+		KeepWeekly:  retention.NoPrune,
+		KeepMonthly: retention.NoPrune,
+		KeepYearly:  retention.NoPrune,
+
+		KeepQuarterly:  retention.NoPrune,
+		KeepHalfYearly: retention.NoPrune,
+
+		PinMarker: retention.DefaultPinMarker,
+
+		MaxPrunePercent: 100,
+	}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	*j = Job(raw)
+	return nil
+}
+
+func (j *Job) Configuration() retention.Configuration {
+	config := retention.NewConfiguration(j.Sources, j.KeepLast, j.KeepHourly, j.KeepDaily, j.KeepWeekly, j.KeepMonthly, j.KeepYearly)
+	config.KeepQuarterly = j.KeepQuarterly
+	config.KeepHalfYearly = j.KeepHalfYearly
+	config.WeekStart = j.WeekStart
+	config.HourlyPick = j.HourlyPick
+	config.DailyPick = j.DailyPick
+	config.WeeklyPick = j.WeeklyPick
+	config.MonthlyPick = j.MonthlyPick
+	config.QuarterlyPick = j.QuarterlyPick
+	config.HalfYearlyPick = j.HalfYearlyPick
+	config.YearlyPick = j.YearlyPick
+	config.Strict = j.Strict
+	config.KeepWithin = j.KeepWithin
+	config.KeepWithinHourly = j.KeepWithinHourly
+	config.KeepWithinDaily = j.KeepWithinDaily
+	config.KeepWithinWeekly = j.KeepWithinWeekly
+	config.KeepWithinMonthly = j.KeepWithinMonthly
+	config.KeepWithinYearly = j.KeepWithinYearly
+	config.MinAge = j.MinAge
+	config.Timezone = j.Timezone
+	return config
+}
+
+// Guard returns the safety guard of the job
+func (j *Job) Guard() Guard {
+	return Guard{MaxPrunePercent: j.MaxPrunePercent, MinKeep: j.MinKeep, AllowPruneNewest: j.AllowPruneNewest}
+}
+
+// ValidityChecks returns the checks defining valid backups of the job
+func (j *Job) ValidityChecks() []retention.ValidityCheck {
+	checks := []retention.ValidityCheck{}
+	for _, glob := range j.RequireGlobs {
+		checks = append(checks, retention.RequiredGlobCheck{Glob: glob})
+	}
+	if j.CompletionMarker != "" {
+		checks = append(checks, retention.CompletionMarkerCheck{Marker: j.CompletionMarker})
+	}
+	if j.VerifySHA256Sum {
+		checks = append(checks, retention.SHA256SumCheck{})
+	}
+	return checks
+}
+
+// Pins returns the names or paths listed in the pin list of the job, nil if
+// the job has no pin list
+func (j *Job) Pins() ([]string, error) {
+	if j.PinList == "" {
+		return nil, nil
+	}
+	return retention.LoadPinList(j.PinList)
+}
+
+// LoadJobFile reads and validates the job file at path
+func LoadJobFile(path string) (JobFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return JobFile{}, err
+	}
+
+	var jobFile JobFile
+	if err := yaml.Unmarshal(content, &jobFile); err != nil {
+		return JobFile{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if err := jobFile.validate(); err != nil {
+		return JobFile{}, fmt.Errorf("invalid job file %s: %w", path, err)
+	}
+
+	return jobFile, nil
+}
+
+func (f *JobFile) validate() error {
+	if len(f.Jobs) == 0 {
+		return fmt.Errorf("no jobs defined")
+	}
+
+	names := make(map[string]bool)
+	for i := range f.Jobs {
+		job := &f.Jobs[i]
+
+		if job.Name == "" {
+			return fmt.Errorf("job #%d: missing name", i+1)
+		}
+		if names[job.Name] {
+			return fmt.Errorf("job %s: defined more than once", job.Name)
+		}
+		names[job.Name] = true
+
+		if _, err := time.LoadLocation(job.Timezone); err != nil {
+			return fmt.Errorf("job %s: invalid timezone: %w", job.Name, err)
+		}
+
+		if err := retention.ValidateStrict(job.Strict); err != nil {
+			return fmt.Errorf("job %s: %w", job.Name, err)
+		}
+
+		if err := validateGuard(job.MaxPrunePercent, job.MinKeep); err != nil {
+			return fmt.Errorf("job %s: %w", job.Name, err)
+		}
+
+		if len(job.Sources) == 0 {
+			return fmt.Errorf("job %s: no sources defined", job.Name)
+		}
+		for j := range job.Sources {
+			source := &job.Sources[j]
+			if source.Path == "" {
+				return fmt.Errorf("job %s: source #%d: missing path", job.Name, j+1)
+			}
+			if source.Pattern == "" {
+				source.Pattern = retention.PatternAlmostISO8601DateAndTime
+			}
+			if _, err := time.LoadLocation(source.DefaultTimezone); err != nil {
+				return fmt.Errorf("job %s: source #%d: invalid default timezone: %w", job.Name, j+1, err)
+			}
+			if source.Regex != "" {
+				if _, err := retention.NewRegexTimeParser(source.Regex, source.Pattern); err != nil {
+					return fmt.Errorf("job %s: source #%d: %w", job.Name, j+1, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Select returns the jobs with the given names, in the order of the names.
+// All jobs are returned if no names are given
+func (f *JobFile) Select(names []string) ([]Job, error) {
+	if len(names) == 0 {
+		return f.Jobs, nil
+	}
+
+	jobs := make([]Job, 0, len(names))
+	for _, name := range names {
+		job, ok := f.find(name)
+		if !ok {
+			return nil, fmt.Errorf("job %s not found", name)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+func (f *JobFile) find(name string) (Job, bool) {
+	for _, job := range f.Jobs {
+		if job.Name == name {
+			return job, true
+		}
+	}
+	return Job{}, false
+}
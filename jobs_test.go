@@ -0,0 +1,221 @@
+package main
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/codezombiech/prune/retention"
+)
+
+func TestLoadJobFile(t *testing.T) {
+	// Arrange
+	jobFilePath := writeJobFile(`
+jobs:
+  - name: db
+    sources:
+      - path: /backups/db
+        pattern: "%Y-%m-%d"
+    keep-hourly: 24
+    keep-daily: 7
+    keep-within-daily: 2w
+    min-age: 12h
+    max-prune-percent: 80
+    min-keep: 3
+    allow-prune-newest: true
+  - name: files
+    sources:
+      - path: /backups/files-old
+      - path: /backups/files-new
+        type: file
+    keep-last: 3
+    keep-monthly: 6
+    monthly-pick: first
+    strict: [monthly]
+    keep-quarterly: 8
+    week-start: sunday
+    timezone: Europe/Zurich
+    pin-marker: ""
+    pin-list: /etc/prune/pins
+    require-globs: ["*.tar.gz"]
+    verify-sha256sum: true
+`, t)
+
+	// Act
+	jobFile, err := LoadJobFile(jobFilePath)
+	if err != nil {
+		t.Fatalf("Failed to load job file: %v", err)
+	}
+
+	// Assert
+	if expected, actual := 2, len(jobFile.Jobs); actual != expected {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+
+	db := jobFile.Jobs[0]
+	if expected, actual := "db", db.Name; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := "/backups/db", db.Sources[0].Path; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := "%Y-%m-%d", db.Sources[0].Pattern; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 24, db.KeepHourly; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 7, db.KeepDaily; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := retention.NoPrune, db.KeepMonthly; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := (retention.Duration{Weeks: 2}), db.KeepWithinDaily; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := (retention.Duration{Hours: 12}), db.Configuration().MinAge; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if !db.KeepWithin.IsZero() {
+		t.Errorf("Expected zero duration, got %v", db.KeepWithin)
+	}
+
+	files := jobFile.Jobs[1]
+	if expected, actual := 2, len(files.Sources); actual != expected {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := retention.PatternAlmostISO8601DateAndTime, files.Sources[1].Pattern; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := retention.EntryTypeDirectory, files.Sources[0].Type; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := retention.EntryTypeFile, files.Sources[1].Type; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := retention.NoPrune, files.KeepDaily; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 3, files.KeepLast; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := retention.NoPrune, db.KeepLast; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 6, files.KeepMonthly; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 8, files.KeepQuarterly; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := retention.NoPrune, files.KeepHalfYearly; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := retention.PickOldest, files.Configuration().MonthlyPick; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := []string{"monthly"}, files.Configuration().Strict; len(actual) != 1 || actual[0] != expected[0] {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := retention.PickNewest, files.Configuration().DailyPick; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := retention.WeekStartSunday, files.Configuration().WeekStart; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := retention.WeekStartMonday, db.WeekStart; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := "Europe/Zurich", files.Configuration().Timezone; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := (Guard{MaxPrunePercent: 80, MinKeep: 3, AllowPruneNewest: true}), db.Guard(); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := (Guard{MaxPrunePercent: 100}), files.Guard(); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := retention.DefaultPinMarker, db.PinMarker; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := "", files.PinMarker; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := "/etc/prune/pins", files.PinList; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := []retention.ValidityCheck{retention.RequiredGlobCheck{Glob: "*.tar.gz"}, retention.SHA256SumCheck{}}, files.ValidityChecks(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 0, len(db.ValidityChecks()); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestLoadJobFileInvalid(t *testing.T) {
+	var testCases = map[string]string{
+		"no jobs":                  `jobs: []`,
+		"missing name":             "jobs:\n  - sources:\n      - path: /backups",
+		"duplicate name":           "jobs:\n  - name: db\n    sources:\n      - path: /a\n  - name: db\n    sources:\n      - path: /b",
+		"no sources":               "jobs:\n  - name: db",
+		"missing path":             "jobs:\n  - name: db\n    sources:\n      - pattern: '%Y'",
+		"invalid yaml":             "jobs: [",
+		"invalid type":             "jobs:\n  - name: db\n    sources:\n      - path: /a\n        type: directory",
+		"invalid timezone":         "jobs:\n  - name: db\n    timezone: Europe/Nowhere\n    sources:\n      - path: /a",
+		"invalid default timezone": "jobs:\n  - name: db\n    sources:\n      - path: /a\n        default-timezone: Europe/Nowhere",
+		"invalid week start":       "jobs:\n  - name: db\n    week-start: sun\n    sources:\n      - path: /a",
+		"invalid pick":             "jobs:\n  - name: db\n    monthly-pick: middle\n    sources:\n      - path: /a",
+		"invalid strict":           "jobs:\n  - name: db\n    strict: [last]\n    sources:\n      - path: /a",
+		"invalid guard":            "jobs:\n  - name: db\n    max-prune-percent: 120\n    sources:\n      - path: /a",
+		"invalid within":           "jobs:\n  - name: db\n    sources:\n      - path: /a\n    keep-within: 7 days",
+	}
+
+	for name, content := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadJobFile(writeJobFile(content, t))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestJobFileSelect(t *testing.T) {
+	// Arrange
+	jobFile := JobFile{Jobs: []Job{{Name: "a"}, {Name: "b"}, {Name: "c"}}}
+
+	// Act
+	all, err := jobFile.Select(nil)
+	if err != nil {
+		t.Fatalf("Failed to select jobs: %v", err)
+	}
+	selected, err := jobFile.Select([]string{"c", "a"})
+	if err != nil {
+		t.Fatalf("Failed to select jobs: %v", err)
+	}
+	_, notFoundErr := jobFile.Select([]string{"d"})
+
+	// Assert
+	if expected, actual := 3, len(all); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 2, len(selected); actual != expected {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := "c", selected[0].Name; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if notFoundErr == nil {
+		t.Errorf("Expected error for unknown job")
+	}
+}
+
+func writeJobFile(content string, t *testing.T) string {
+	jobFilePath := path.Join(t.TempDir(), "prune.yaml")
+	if err := os.WriteFile(jobFilePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write job file: %v", err)
+	}
+	return jobFilePath
+}
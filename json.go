@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/codezombiech/prune/retention"
+)
+
+// JSONDocument is the document written to stdout when using the --json flag
+type JSONDocument struct {
+	Configuration retention.Configuration `json:"configuration"`
+	Candidates    []JSONCandidate         `json:"candidates"`
+	Stats         JSONStats               `json:"stats"`
+}
+
+// JSONJobsDocument is the document written to stdout when using the --json
+// flag together with the --config flag
+type JSONJobsDocument struct {
+	Jobs []JSONJobDocument `json:"jobs"`
+}
+
+type JSONJobDocument struct {
+	Name string `json:"name"`
+	JSONDocument
+}
+
+type JSONCandidate struct {
+	Name      string                `json:"name"`
+	Path      string                `json:"path"`
+	Source    string                `json:"source"`
+	Series    string                `json:"series,omitempty"`
+	IsDir     bool                  `json:"isDir"`
+	Time      time.Time             `json:"time"`
+	Keep      bool                  `json:"keep"`
+	Operation string                `json:"operation"`
+	Reason    *retention.KeepReason `json:"reason,omitempty"`
+}
+
+type JSONStats struct {
+	Total   int               `json:"total"`
+	Keep    int               `json:"keep"`
+	Prune   int               `json:"prune"`
+	Sources []JSONSourceStats `json:"sources"`
+	Series  []JSONSeriesStats `json:"series,omitempty"`
+	// OldestFallbacks lists the files/directories kept because a rule could not
+	// satisfy its keep count otherwise
+	OldestFallbacks []JSONOldestFallback `json:"oldestFallbacks"`
+	// Invalid lists the files/directories failing a validity check
+	Invalid []JSONInvalid `json:"invalid"`
+}
+
+type JSONOldestFallback struct {
+	Path string `json:"path"`
+	Rule string `json:"rule"`
+}
+
+type JSONInvalid struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+type JSONSeriesStats struct {
+	Name  string `json:"name"`
+	Total int    `json:"total"`
+	Keep  int    `json:"keep"`
+	Prune int    `json:"prune"`
+}
+
+type JSONSourceStats struct {
+	Path  string `json:"path"`
+	Total int    `json:"total"`
+	Keep  int    `json:"keep"`
+	Prune int    `json:"prune"`
+}
+
+func NewJSONDocument(config retention.Configuration, result retention.PruneResult) JSONDocument {
+	candidates := make([]JSONCandidate, 0, len(result.Objects))
+	for _, object := range result.Objects {
+		candidates = append(candidates, JSONCandidate{
+			Name:      object.Object.Name,
+			Path:      object.Object.Path,
+			Source:    object.Object.BasePath,
+			Series:    object.Object.Series,
+			IsDir:     object.Object.IsDir,
+			Time:      object.Object.Time,
+			Keep:      object.Keep,
+			Operation: operationName(object.Keep),
+			Reason:    object.Reason,
+		})
+	}
+
+	// Sort by path to produce the same order as the plain text output
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Path < candidates[j].Path
+	})
+
+	sources := make([]JSONSourceStats, 0, len(config.Sources))
+	for _, source := range config.Sources {
+		keep, prune := countBySource(source, result)
+		sources = append(sources, JSONSourceStats{Path: source.Path, Total: keep + prune, Keep: keep, Prune: prune})
+	}
+
+	series := []JSONSeriesStats{}
+	if names := result.Series(); hasSeries(names) {
+		for _, name := range names {
+			keep, prune := countBySeries(name, result)
+			series = append(series, JSONSeriesStats{Name: name, Total: keep + prune, Keep: keep, Prune: prune})
+		}
+	}
+
+	fallbacks := []JSONOldestFallback{}
+	for _, object := range result.OldestFallbacks() {
+		fallbacks = append(fallbacks, JSONOldestFallback{Path: object.Object.Path, Rule: object.Reason.Rule})
+	}
+
+	invalid := []JSONInvalid{}
+	for _, object := range result.Invalid() {
+		invalid = append(invalid, JSONInvalid{Path: object.Object.Path, Reason: object.Object.Invalid})
+	}
+
+	return JSONDocument{
+		Configuration: config,
+		Candidates:    candidates,
+		Stats: JSONStats{
+			Total:   len(result.Objects),
+			Keep:    len(result.ToKeep),
+			Prune:   len(result.ToPrune),
+			Sources: sources,
+			Series:  series,
+
+			OldestFallbacks: fallbacks,
+			Invalid:         invalid,
+		},
+	}
+}
+
+func printJSON(document interface{}) error {
+	encoder := json.NewEncoder(logger.Writer())
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
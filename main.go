@@ -15,10 +15,16 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 
+	"github.com/codezombiech/prune/retention"
 	flag "github.com/spf13/pflag"
 )
 
@@ -26,12 +32,55 @@ var (
 	logger      *log.Logger
 	errorLogger *log.Logger
 
-	baseDirectory string
-	verbose       bool
-	keepDaily     int
-	keepMonthly   int
-	keepYearly    int
-	pattern       string
+	baseDirectories   []string
+	configFile        string
+	jobNames          []string
+	verbose           bool
+	null              bool
+	jsonOutput        bool
+	deleteFlag        bool
+	dryRun            bool
+	moveTo            string
+	force             bool
+	maxPrunePercent   float64
+	minKeep           int
+	allowPruneNewest  bool
+	now               string
+	timezone          string
+	defaultTimezone   string
+	keepLast          int
+	keepHourly        int
+	keepDaily         int
+	keepWeekly        int
+	keepMonthly       int
+	keepYearly        int
+	keepQuarterly     int
+	keepHalfYearly    int
+	weekStart         string
+	strict            []string
+	pinMarker         string
+	pinList           string
+	requireGlobs      []string
+	completionMarker  string
+	verifySHA256Sum   bool
+	hourlyPick        string
+	dailyPick         string
+	weeklyPick        string
+	monthlyPick       string
+	quarterlyPick     string
+	halfYearlyPick    string
+	yearlyPick        string
+	keepWithin        string
+	keepWithinHourly  string
+	keepWithinDaily   string
+	keepWithinWeekly  string
+	keepWithinMonthly string
+	keepWithinYearly  string
+	minAge            string
+	patterns          []string
+	entryType         string
+	regex             string
+	seriesPrefixes    []string
 )
 
 func init() {
@@ -39,77 +88,478 @@ func init() {
 	errorLogger = log.New(os.Stderr, "", 0)
 
 	flag.BoolVarP(&verbose, "verbose", "v", false, "verbose flag")
+	flag.StringVarP(&configFile, "config", "c", "", "YAML file defining named prune jobs. Positional arguments select the jobs to run (default: all jobs)")
+	flag.BoolVarP(&null, "null", "0", false, "terminate paths with a NUL character instead of a newline (e.g. for xargs -0)")
+	flag.BoolVar(&jsonOutput, "json", false, "write configuration, all files/directories with their keep/prune decision and statistics as JSON to stdout")
+	flag.BoolVar(&deleteFlag, "delete", false, "delete files/directories to prune")
+	flag.BoolVar(&dryRun, "dry-run", false, "used with --delete or --move-to, report files/directories that would be deleted/moved without deleting/moving them")
+	flag.StringVar(&moveTo, "move-to", "", "move files/directories to prune into this quarantine directory instead of deleting them (see prune-purge)")
+	flag.BoolVar(&force, "force", false, "prune even if a safety guard (newest file/directory, --max-prune-percent or --min-keep) refuses to")
+	flag.Float64Var(&maxPrunePercent, "max-prune-percent", 100, "refuse to prune if more than this percentage of the files/directories would be pruned")
+	flag.IntVar(&minKeep, "min-keep", 0, "refuse to prune if fewer files/directories would be kept")
+	flag.BoolVar(&allowPruneNewest, "allow-prune-newest", false, "allow pruning the newest file/directory, e.g. with --*-pick oldest, keeping the other safety guards")
+	flag.StringVar(&now, "now", "", "reference time of age based rules like --keep-within as RFC 3339 timestamp, e.g. 2000-01-01T00:00:00Z (default: current time)")
 
+	flag.IntVarP(&keepLast, "keep-last", "l", -1, "number of most recent files/directories to keep")
+	flag.IntVarP(&keepHourly, "keep-hourly", "H", -1, "number of hourly files/directories to keep")
 	flag.IntVarP(&keepDaily, "keep-daily", "d", -1, "number of daily files/directories to keep")
+	flag.IntVarP(&keepWeekly, "keep-weekly", "w", -1, "number of weekly (ISO 8601 week) files/directories to keep")
 	flag.IntVarP(&keepMonthly, "keep-monthly", "m", -1, "number of monthly files/directories to keep")
+	flag.IntVarP(&keepQuarterly, "keep-quarterly", "q", -1, "number of quarterly files/directories to keep")
+	flag.IntVar(&keepHalfYearly, "keep-half-yearly", -1, "number of half-yearly files/directories to keep")
 	flag.IntVarP(&keepYearly, "keep-yearly", "y", -1, "number of yearly files/directories to keep")
+	flag.StringVar(&hourlyPick, "hourly-pick", "newest", "file/directory kept per hour: newest (or last) or oldest (or first)")
+	flag.StringVar(&dailyPick, "daily-pick", "newest", "file/directory kept per day: newest (or last) or oldest (or first)")
+	flag.StringVar(&weeklyPick, "weekly-pick", "newest", "file/directory kept per week: newest (or last) or oldest (or first)")
+	flag.StringVar(&monthlyPick, "monthly-pick", "newest", "file/directory kept per month: newest (or last) or oldest (or first)")
+	flag.StringVar(&quarterlyPick, "quarterly-pick", "newest", "file/directory kept per quarter: newest (or last) or oldest (or first)")
+	flag.StringVar(&halfYearlyPick, "half-yearly-pick", "newest", "file/directory kept per half-year: newest (or last) or oldest (or first)")
+	flag.StringVar(&yearlyPick, "yearly-pick", "newest", "file/directory kept per year: newest (or last) or oldest (or first)")
+	flag.StringSliceVar(&strict, "strict", []string{}, "rules (hourly, daily, weekly, monthly, quarterly, half-yearly, yearly or all) not keeping the oldest file/directory if their keep count cannot be satisfied otherwise")
+	flag.StringVar(&pinMarker, "pin-marker", retention.DefaultPinMarker, "name of the marker file pinning the directory containing it, pinned directories are always kept and do not count towards any keep count (empty to disable)")
+	flag.StringVar(&pinList, "pin-list", "", "file containing the name or path of a file/directory to pin per line")
+	flag.StringArrayVar(&requireGlobs, "require-glob", []string{}, "glob a directory must contain an entry matching to be a valid backup, e.g. '*.tar.gz'. Can be specified multiple times")
+	flag.StringVar(&completionMarker, "completion-marker", "", "name of the file a directory must contain (or suffix of the file next to a file) to be a valid backup, e.g. .complete")
+	flag.BoolVar(&verifySHA256Sum, "verify-sha256sum", false, "verify the *.sha256sum files inside directories (or next to files), backups with a checksum mismatch are invalid")
+	flag.StringVar(&weekStart, "week-start", "monday", "first day of the weeks of the weekly rules, e.g. sunday (weeks starting on monday are ISO 8601 weeks)")
 
+	flag.StringVar(&keepWithin, "keep-within", "", "keep all files/directories within a duration (e.g. 7d, 2w, 3m, 1y or 1y6m) before --now")
+	flag.StringVar(&keepWithinHourly, "keep-within-hourly", "", "keep one file/directory per hour within a duration before --now")
+	flag.StringVar(&keepWithinDaily, "keep-within-daily", "", "keep one file/directory per day within a duration before --now")
+	flag.StringVar(&keepWithinWeekly, "keep-within-weekly", "", "keep one file/directory per week within a duration before --now")
+	flag.StringVar(&keepWithinMonthly, "keep-within-monthly", "", "keep one file/directory per month within a duration before --now")
+	flag.StringVar(&keepWithinYearly, "keep-within-yearly", "", "keep one file/directory per year within a duration before --now")
+	flag.StringVar(&minAge, "min-age", "", "keep all files/directories younger than a duration (e.g. 12h or 1d) before --now, independent of the keep rules")
+
+	flag.StringVar(&timezone, "timezone", "", "IANA time zone name (e.g. Europe/Zurich) or Local, timestamps are converted to before applying the rules, e.g. to define the days of --keep-daily (default: time zone of the timestamps)")
+	flag.StringVar(&defaultTimezone, "default-timezone", "", "IANA time zone name or Local of timestamps without time zone (default: UTC)")
+
+	flag.StringVarP(&entryType, "type", "t", "dir", "type of timestamped entries to consider: dir, file or any")
+
+	flag.StringVarP(&regex, "regex", "r", "", "regular expression with named groups (year, month, day, hour, minute, second, tz or a single ts group parsed using --pattern) used to extract the date from an arbitrary part of the name")
+	flag.StringArrayVar(&seriesPrefixes, "series-prefix", []string{}, "name prefix defining a series, retention is applied to each series independently. Can be specified multiple times, files/directories not matching any prefix are ignored")
 	// TODO: evaluate sane default (if a default makes sense at all)
-	flag.StringVarP(&pattern, "pattern", "p", PatternAlmostISO8601DateAndTime, "strptime pattern used to parse the date from the name of the timestamped directory")
+	flag.StringArrayVarP(&patterns, "pattern", "p", []string{retention.PatternAlmostISO8601DateAndTime}, "strptime pattern used to parse the date from the name of the timestamped file/directory. Specify once for all directories or once per directory (in the same order)")
 }
 
 func main() {
 	// Parse
 	flag.Parse()
 
-	if flag.NArg() != 1 {
-		errorLogger.Printf("To many arguments")
-		os.Exit(2) // Aligns with pflag "ExitOnError will call os.Exit(2) if an error is found when parsing"
+	if configFile != "" {
+		jobNames = flag.Args()
+	} else {
+		if flag.NArg() < 1 {
+			errorLogger.Printf("Missing argument: provide at least one directory")
+			os.Exit(2) // Aligns with pflag "ExitOnError will call os.Exit(2) if an error is found when parsing"
+		}
+		baseDirectories = flag.Args()
 	}
-	baseDirectory = flag.Args()[0]
 
 	// Validate
+	if configFile != "" {
+		// Jobs define their own patterns and keep counts
+		for _, name := range []string{"pattern", "regex", "series-prefix", "type", "keep-last", "keep-hourly", "keep-daily", "keep-weekly", "keep-monthly", "keep-quarterly", "keep-half-yearly", "keep-yearly", "strict", "week-start", "hourly-pick", "daily-pick", "weekly-pick", "monthly-pick", "quarterly-pick", "half-yearly-pick", "yearly-pick", "keep-within", "keep-within-hourly", "keep-within-daily", "keep-within-weekly", "keep-within-monthly", "keep-within-yearly", "min-age", "timezone", "default-timezone", "pin-marker", "pin-list", "require-glob", "completion-marker", "verify-sha256sum", "max-prune-percent", "min-keep", "allow-prune-newest"} {
+			if flag.CommandLine.Changed(name) {
+				errorLogger.Printf("--%s cannot be combined with --config", name)
+				os.Exit(2)
+			}
+		}
+	} else {
+		if len(patterns) != 1 && len(patterns) != len(baseDirectories) {
+			errorLogger.Printf("Invalid number of patterns: provide a single pattern or one pattern per directory")
+			os.Exit(2)
+		}
+		if duplicate, ok := findDuplicate(baseDirectories); ok {
+			errorLogger.Printf("Directory %s provided more than once", duplicate)
+			os.Exit(2)
+		}
+		if err := validateGuard(maxPrunePercent, minKeep); err != nil {
+			errorLogger.Printf("Invalid guard: %v", err)
+			os.Exit(2)
+		}
+		if _, err := retention.ParseEntryType(entryType); err != nil {
+			errorLogger.Printf("Invalid --type: %v", err)
+			os.Exit(2)
+		}
+		for name, value := range map[string]string{"keep-within": keepWithin, "keep-within-hourly": keepWithinHourly, "keep-within-daily": keepWithinDaily, "keep-within-weekly": keepWithinWeekly, "keep-within-monthly": keepWithinMonthly, "keep-within-yearly": keepWithinYearly, "min-age": minAge} {
+			if _, err := retention.ParseDuration(value); err != nil {
+				errorLogger.Printf("Invalid --%s: %v", name, err)
+				os.Exit(2)
+			}
+		}
+		for name, value := range map[string]string{"hourly-pick": hourlyPick, "daily-pick": dailyPick, "weekly-pick": weeklyPick, "monthly-pick": monthlyPick, "quarterly-pick": quarterlyPick, "half-yearly-pick": halfYearlyPick, "yearly-pick": yearlyPick} {
+			if _, err := retention.ParsePick(value); err != nil {
+				errorLogger.Printf("Invalid --%s: %v", name, err)
+				os.Exit(2)
+			}
+		}
+		if err := retention.ValidateStrict(strict); err != nil {
+			errorLogger.Printf("Invalid --strict: %v", err)
+			os.Exit(2)
+		}
+		if _, err := retention.ParseWeekStart(weekStart); err != nil {
+			errorLogger.Printf("Invalid --week-start: %v", err)
+			os.Exit(2)
+		}
+		for name, value := range map[string]string{"timezone": timezone, "default-timezone": defaultTimezone} {
+			if _, err := time.LoadLocation(value); err != nil {
+				errorLogger.Printf("Invalid --%s: %v", name, err)
+				os.Exit(2)
+			}
+		}
+		if regex != "" {
+			for _, pattern := range patterns {
+				parser, err := retention.NewRegexTimeParser(regex, pattern)
+				if err != nil {
+					errorLogger.Printf("Invalid --regex: %v", err)
+					os.Exit(2)
+				}
+				if parser.HasSeries() && len(seriesPrefixes) > 0 {
+					errorLogger.Printf("--series-prefix cannot be combined with a --regex containing a '%s' group", retention.RegexGroupSeries)
+					os.Exit(2)
+				}
+			}
+		}
+	}
+	if jsonOutput && null {
+		errorLogger.Printf("--json and --null cannot be combined")
+		os.Exit(2)
+	}
+	if deleteFlag && moveTo != "" {
+		errorLogger.Printf("--delete and --move-to cannot be combined")
+		os.Exit(2)
+	}
+	if dryRun && !deleteFlag && moveTo == "" {
+		errorLogger.Printf("--dry-run requires --delete or --move-to")
+		os.Exit(2)
+	}
+	if now != "" {
+		if _, err := time.Parse(time.RFC3339, now); err != nil {
+			errorLogger.Printf("Invalid --now: %v", err)
+			os.Exit(2)
+		}
+	}
 
 	// Run
 	if err := run(); err != nil {
+		var deleteErr *DeleteError
+		if errors.As(err, &deleteErr) {
+			errorLogger.Printf("%v", err)
+			os.Exit(ExitCodeDeleteFailed)
+		}
+		var moveErr *MoveError
+		if errors.As(err, &moveErr) {
+			errorLogger.Printf("%v", err)
+			os.Exit(ExitCodeDeleteFailed)
+		}
+		var guardErr *GuardError
+		if errors.As(err, &guardErr) {
+			errorLogger.Printf("%v", err)
+			os.Exit(ExitCodeGuardFailed)
+		}
 		errorLogger.Printf("Shit hit the fan: %v", err)
 		os.Exit(1)
 	}
 }
 
 func run() error {
-	if verbose {
-		logger.Printf("keep-daily: %v, keep-monthly: %v, keep-yearly: %v", keepDaily, keepMonthly, keepYearly)
+	jobs, err := createJobs()
+	if err != nil {
+		errorLogger.Printf("Failed to load jobs")
+		return err
+	}
+
+	// Use the same reference time for all jobs
+	reference := referenceTime()
+
+	// Calculate all jobs and check their guards before producing any output
+	results := make([]jobResult, 0, len(jobs))
+	for _, job := range jobs {
+		config := job.Configuration()
+		config.Now = reference
+
+		pins, err := job.Pins()
+		if err != nil {
+			errorLogger.Printf("Failed to load pin list %s", job.PinList)
+			return err
+		}
+
+		traverser := retention.FileSystemTraverser{PinMarker: job.PinMarker, Pins: pins, ValidityChecks: job.ValidityChecks()}
+		pruneResult, err := calculate(config, traverser)
+		if err != nil {
+			return err
+		}
+
+		if !force {
+			if err := job.Guard().check(job.Name, pruneResult); err != nil {
+				return err
+			}
+		}
+
+		results = append(results, jobResult{job: job, config: config, pins: pins, pruneResult: pruneResult})
+	}
+
+	toPrune := []retention.PruneCandidate{}
+	jsonJobs := make([]JSONJobDocument, 0, len(jobs))
+	for _, result := range results {
+		job, config, pins, pruneResult := result.job, result.config, result.pins, result.pruneResult
+
+		if job.Name != "" && !jsonOutput {
+			errorLogger.Printf("[%s]\n", job.Name)
+		}
+		if verbose && !jsonOutput {
+			logger.Printf("keep-last: %v, keep-hourly: %v, keep-daily: %v, keep-weekly: %v, keep-monthly: %v, keep-quarterly: %v, keep-half-yearly: %v, keep-yearly: %v", config.KeepLast, config.KeepHourly, config.KeepDaily, config.KeepWeekly, config.KeepMonthly, config.KeepQuarterly, config.KeepHalfYearly, config.KeepYearly)
+			if config.WeekStart != retention.WeekStartMonday {
+				logger.Printf("week-start: %v", config.WeekStart)
+			}
+			printPicks(config)
+			if len(config.Strict) > 0 {
+				logger.Printf("strict: %v", strings.Join(config.Strict, ", "))
+			}
+			printKeepWithin(config)
+			logger.Printf("now: %v", config.Now.Format(time.RFC3339))
+			if config.Timezone != "" {
+				logger.Printf("timezone: %v", config.Timezone)
+			}
+			if job.PinMarker != "" {
+				logger.Printf("pin-marker: %v", job.PinMarker)
+			}
+			if job.PinList != "" {
+				logger.Printf("pin-list: %v (%d pins)", job.PinList, len(pins))
+			}
+			if len(job.RequireGlobs) > 0 || job.CompletionMarker != "" || job.VerifySHA256Sum {
+				logger.Printf("require-glob: %v, completion-marker: %v, verify-sha256sum: %v", strings.Join(job.RequireGlobs, ", "), job.CompletionMarker, job.VerifySHA256Sum)
+			}
+			if guard := job.Guard(); guard.MaxPrunePercent < 100 || guard.MinKeep > 0 || guard.AllowPruneNewest {
+				logger.Printf("max-prune-percent: %v, min-keep: %v, allow-prune-newest: %v", guard.MaxPrunePercent, guard.MinKeep, guard.AllowPruneNewest)
+			}
+		}
+
+		if jsonOutput {
+			jsonJobs = append(jsonJobs, JSONJobDocument{Name: job.Name, JSONDocument: NewJSONDocument(config, pruneResult)})
+		} else {
+			printSorted(config.Sources, pruneResult)
+
+			// Reported on stderr, so stdout stays a plain list of paths
+			for _, object := range pruneResult.Invalid() {
+				errorLogger.Printf("Invalid %s: %s, kept and not counted by any rule\n", object.Object.Path, object.Object.Invalid)
+			}
+
+			if verbose {
+				printStats(config.Sources, pruneResult)
+			}
+		}
+
+		toPrune = append(toPrune, pruneResult.ToPrune...)
+	}
+
+	if jsonOutput {
+		var err error
+		if configFile != "" {
+			err = printJSON(JSONJobsDocument{Jobs: jsonJobs})
+		} else {
+			err = printJSON(jsonJobs[0].JSONDocument)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if deleteFlag {
+		return deleteObjects(toPrune, dryRun)
+	}
+	if moveTo != "" {
+		return moveObjects(toPrune, moveTo, dryRun)
+	}
+
+	return err
+}
+
+type jobResult struct {
+	job         Job
+	config      retention.Configuration
+	pins        []string
+	pruneResult retention.PruneResult
+}
+
+// createJobs returns the jobs selected from the job file or a single unnamed
+// job defined by the CLI arguments
+func createJobs() ([]Job, error) {
+	if configFile != "" {
+		jobFile, err := LoadJobFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		return jobFile.Select(jobNames)
 	}
 
 	// TODO: validate pattern
 
-	// Create config
-	config := Configuration{
-		Path:        baseDirectory,
-		Pattern:     pattern,
-		KeepDaily:   keepDaily,
-		KeepMonthly: keepMonthly,
-		KeepYearly:  keepYearly,
+	job := Job{
+		Sources:           createSources(baseDirectories, patterns, regex, entryType),
+		KeepLast:          keepLast,
+		KeepHourly:        keepHourly,
+		KeepDaily:         keepDaily,
+		KeepWeekly:        keepWeekly,
+		KeepMonthly:       keepMonthly,
+		KeepQuarterly:     keepQuarterly,
+		KeepHalfYearly:    keepHalfYearly,
+		KeepYearly:        keepYearly,
+		WeekStart:         parseWeekStart(weekStart),
+		Strict:            strict,
+		HourlyPick:        parsePick(hourlyPick),
+		DailyPick:         parsePick(dailyPick),
+		WeeklyPick:        parsePick(weeklyPick),
+		MonthlyPick:       parsePick(monthlyPick),
+		QuarterlyPick:     parsePick(quarterlyPick),
+		HalfYearlyPick:    parsePick(halfYearlyPick),
+		YearlyPick:        parsePick(yearlyPick),
+		KeepWithin:        parseDuration(keepWithin),
+		KeepWithinHourly:  parseDuration(keepWithinHourly),
+		KeepWithinDaily:   parseDuration(keepWithinDaily),
+		KeepWithinWeekly:  parseDuration(keepWithinWeekly),
+		KeepWithinMonthly: parseDuration(keepWithinMonthly),
+		KeepWithinYearly:  parseDuration(keepWithinYearly),
+		MinAge:            parseDuration(minAge),
+		Timezone:          timezone,
+		PinMarker:         pinMarker,
+		PinList:           pinList,
+		RequireGlobs:      requireGlobs,
+		CompletionMarker:  completionMarker,
+		VerifySHA256Sum:   verifySHA256Sum,
+		MaxPrunePercent:   maxPrunePercent,
+		MinKeep:           minKeep,
+		AllowPruneNewest:  allowPruneNewest,
 	}
+	return []Job{job}, nil
+}
 
-	traverser := FileSystemTraverser{Pattern: pattern}
-	objects, err := traverser.GetObjects(baseDirectory)
-	if err != nil {
-		errorLogger.Printf("Failed to retrieve directories")
-		return err
+// referenceTime returns the time passed using --now or the current time
+func referenceTime() time.Time {
+	if now == "" {
+		return time.Now()
+	}
+	// Validated in main
+	reference, _ := time.Parse(time.RFC3339, now)
+	return reference
+}
+
+// calculate applies config to the files/directories of its sources, found
+// using traverser configured with the settings of each source
+func calculate(config retention.Configuration, traverser retention.FileSystemTraverser) (retention.PruneResult, error) {
+	objects := []retention.TimeStampedObject{}
+	for _, source := range config.Sources {
+		location, err := time.LoadLocation(source.DefaultTimezone)
+		if err != nil {
+			errorLogger.Printf("Invalid default timezone of %s", source.Path)
+			return retention.PruneResult{}, err
+		}
+		traverser.Pattern = source.Pattern
+		traverser.Regex = source.Regex
+		traverser.SeriesPrefixes = source.SeriesPrefixes
+		traverser.Type = source.Type
+		traverser.Location = location
+		sourceObjects, err := traverser.GetObjects(source.Path)
+		if err != nil {
+			errorLogger.Printf("Failed to retrieve files/directories of %s", source.Path)
+			return retention.PruneResult{}, err
+		}
+		objects = append(objects, sourceObjects...)
 	}
 
-	prune := NewPrune(config)
+	prune := retention.NewPrune(config)
 	pruneResult, err := prune.Calculate(objects)
 	if err != nil {
-		errorLogger.Printf("Failed to calculate directories to prune")
-		return err
+		errorLogger.Printf("Failed to calculate files/directories to prune")
+		return retention.PruneResult{}, err
 	}
 
-	printSorted(pruneResult.Objects)
+	return pruneResult, nil
+}
+
+func createSources(directories []string, patterns []string, regex string, entryTypeName string) []retention.Source {
+	// Validated in main
+	entryType, _ := retention.ParseEntryType(entryTypeName)
+
+	sources := make([]retention.Source, 0, len(directories))
+	for i, directory := range directories {
+		// Either a single pattern for all directories or one per directory
+		pattern := patterns[0]
+		if len(patterns) == len(directories) {
+			pattern = patterns[i]
+		}
+		sources = append(sources, retention.Source{Path: directory, Pattern: pattern, Regex: regex, SeriesPrefixes: seriesPrefixes, Type: entryType, DefaultTimezone: defaultTimezone})
+	}
+	return sources
+}
 
-	if verbose {
-		printStats(pruneResult)
+// validateGuard returns an error if the thresholds of a Guard are out of range
+func validateGuard(maxPrunePercent float64, minKeep int) error {
+	if maxPrunePercent < 0 || maxPrunePercent > 100 {
+		return fmt.Errorf("max prune percent %v not between 0 and 100", maxPrunePercent)
 	}
+	if minKeep < 0 {
+		return fmt.Errorf("min keep %d is negative", minKeep)
+	}
+	return nil
+}
 
-	return err
+func parseDuration(value string) retention.Duration {
+	// Validated in main
+	duration, _ := retention.ParseDuration(value)
+	return duration
+}
+
+func parseWeekStart(value string) retention.WeekStart {
+	// Validated in main
+	weekStart, _ := retention.ParseWeekStart(value)
+	return weekStart
+}
+
+func parsePick(value string) retention.Pick {
+	// Validated in main
+	pick, _ := retention.ParsePick(value)
+	return pick
+}
+
+func findDuplicate(directories []string) (string, bool) {
+	seen := make(map[string]bool)
+	for _, directory := range directories {
+		cleaned := filepath.Clean(directory)
+		if seen[cleaned] {
+			return directory, true
+		}
+		seen[cleaned] = true
+	}
+	return "", false
 }
 
-func printSorted(objects map[string]*PruneCandidate) {
+// printSorted prints the candidates grouped by source in the order the
+// sources were provided and by series
+func printSorted(sources []retention.Source, result retention.PruneResult) {
+	series := result.Series()
+	for _, source := range sources {
+		if verbose && len(sources) > 1 {
+			errorLogger.Printf("%s:\n", source.Path)
+		}
+		for _, name := range series {
+			if verbose && hasSeries(series) {
+				errorLogger.Printf("series %q:\n", name)
+			}
+			printSortedOf(result.Objects, func(object retention.TimeStampedObject) bool {
+				return object.BasePath == source.Path && object.Series == name
+			})
+		}
+	}
+}
+
+func printSortedOf(objects map[string]*retention.PruneCandidate, filter func(object retention.TimeStampedObject) bool) {
 	keys := make([]string, 0, len(objects))
-	for k := range objects {
-		keys = append(keys, k)
+	for k, object := range objects {
+		if filter(object.Object) {
+			keys = append(keys, k)
+		}
 	}
 
 	sort.Strings(keys)
@@ -118,23 +568,122 @@ func printSorted(objects map[string]*PruneCandidate) {
 		object := objects[k]
 
 		if verbose {
-			var operation string
-			switch object.Keep {
-			case true:
-				operation = "keep"
-			case false:
-				operation = "prune"
-			}
-			errorLogger.Printf("%s: %s\n", object.Directory.Path, operation)
+			if object.Reason != nil {
+				errorLogger.Printf("%s: %s (%v)\n", object.Object.Path, operationName(object.Keep), object.Reason)
+			} else {
+				errorLogger.Printf("%s: %s\n", object.Object.Path, operationName(object.Keep))
+			}
 		} else {
 			// Print only directories to prune
 			if !object.Keep {
-				logger.Println(object.Directory.Path)
+				printPath(object.Object.Path)
 			}
 		}
 	}
 }
 
-func printStats(result PruneResult) {
+// hasSeries reports whether series were defined, i.e. not all objects belong
+// to the default series
+func hasSeries(series []string) bool {
+	return len(series) > 1 || (len(series) == 1 && series[0] != "")
+}
+
+func operationName(keep bool) string {
+	if keep {
+		return "keep"
+	}
+	return "prune"
+}
+
+func printPath(path string) {
+	if null {
+		// Bypass logger, as it appends a newline to every entry
+		fmt.Fprint(logger.Writer(), path, "\x00")
+	} else {
+		logger.Println(path)
+	}
+}
+
+func printPicks(config retention.Configuration) {
+	for _, setting := range []struct {
+		name string
+		pick retention.Pick
+	}{
+		{"hourly-pick", config.HourlyPick},
+		{"daily-pick", config.DailyPick},
+		{"weekly-pick", config.WeeklyPick},
+		{"monthly-pick", config.MonthlyPick},
+		{"quarterly-pick", config.QuarterlyPick},
+		{"half-yearly-pick", config.HalfYearlyPick},
+		{"yearly-pick", config.YearlyPick},
+	} {
+		if setting.pick != retention.PickNewest {
+			logger.Printf("%s: %v", setting.name, setting.pick)
+		}
+	}
+}
+
+func printKeepWithin(config retention.Configuration) {
+	for _, setting := range []struct {
+		name   string
+		within retention.Duration
+	}{
+		{"keep-within", config.KeepWithin},
+		{"keep-within-hourly", config.KeepWithinHourly},
+		{"keep-within-daily", config.KeepWithinDaily},
+		{"keep-within-weekly", config.KeepWithinWeekly},
+		{"keep-within-monthly", config.KeepWithinMonthly},
+		{"keep-within-yearly", config.KeepWithinYearly},
+		{"min-age", config.MinAge},
+	} {
+		if !setting.within.IsZero() {
+			logger.Printf("%s: %v", setting.name, setting.within)
+		}
+	}
+}
+
+func printStats(sources []retention.Source, result retention.PruneResult) {
+	if len(sources) > 1 {
+		for _, source := range sources {
+			keep, prune := countBySource(source, result)
+			logger.Printf("Total count %s: keep: %v, prune: %v\n", source.Path, keep, prune)
+		}
+	}
+	if series := result.Series(); hasSeries(series) {
+		for _, name := range series {
+			keep, prune := countBySeries(name, result)
+			logger.Printf("Total count series %q: keep: %v, prune: %v\n", name, keep, prune)
+		}
+	}
 	logger.Printf("Total count: keep: %v, prune: %v\n", len(result.ToKeep), len(result.ToPrune))
+	for _, object := range result.OldestFallbacks() {
+		logger.Printf("Kept oldest %s: %s rule did not find enough buckets to satisfy its keep count (use --strict %s to disable)\n", object.Object.Path, object.Reason.Rule, object.Reason.Rule)
+	}
+}
+
+func countBySource(source retention.Source, result retention.PruneResult) (int, int) {
+	return countBy(result, func(object retention.TimeStampedObject) bool {
+		return object.BasePath == source.Path
+	})
+}
+
+func countBySeries(series string, result retention.PruneResult) (int, int) {
+	return countBy(result, func(object retention.TimeStampedObject) bool {
+		return object.Series == series
+	})
+}
+
+func countBy(result retention.PruneResult, filter func(object retention.TimeStampedObject) bool) (int, int) {
+	keep, prune := 0, 0
+	for _, object := range result.ToKeep {
+		if filter(object.Object) {
+			keep++
+		}
+	}
+	for _, object := range result.ToPrune {
+		if filter(object.Object) {
+			prune++
+		}
+	}
+	return keep, prune
 }
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -8,6 +11,9 @@ import (
 	"path"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/codezombiech/prune/retention"
 )
 
 func TestPrune(t *testing.T) {
@@ -77,3 +83,777 @@ func pruneFiles(filesToPrune []string, t *testing.T) {
 		}
 	}
 }
+
+func TestPruneNullTerminated(t *testing.T) {
+	repoPath := t.TempDir()
+
+	// Arrange
+	pattern := "backup %Y-%m-%d\nnight %H"
+	directories := map[string]bool{
+		"backup 2000-01-01\nnight 01": false,
+		"backup 2000-01-02\nnight 01": false,
+		"backup 2000-01-03\nnight 01": true,
+		"backup 2000-01-04\nnight 01": true,
+	}
+	for name := range directories {
+		if err := os.Mkdir(path.Join(repoPath, name), 0755); err != nil {
+			t.Fatalf("Failed to create directory %q: %v", name, err)
+		}
+	}
+
+	// Act
+	pruneArgs := []string{"-0", "--pattern", pattern, "-d", "2", repoPath}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("prune failed with %v", err)
+	}
+
+	xargs := exec.Command("xargs", "-0", "rm", "-rf")
+	xargs.Stdin = bytes.NewReader(out)
+	if err := xargs.Run(); err != nil {
+		t.Fatalf("xargs -0 rm -rf failed with %v", err)
+	}
+
+	// Assert
+	if expected, actual := 2, strings.Count(string(out), "\x00"); actual != expected {
+		t.Errorf("Expected %v NUL terminated paths, got %v", expected, actual)
+	}
+
+	for name, expected := range directories {
+		_, err := os.Stat(path.Join(repoPath, name))
+		if exists := err == nil; exists != expected {
+			t.Errorf("%q: Got exists %v, expected %v", name, exists, expected)
+		}
+	}
+}
+
+func TestPruneJSON(t *testing.T) {
+	repoPath := t.TempDir()
+
+	createRepo(repoPath, t)
+
+	// Act
+	pruneArgs := []string{"--json", "-d", "3", "-m", "2", "-y", "1", repoPath}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("prune failed with %v", err)
+	}
+
+	var document JSONDocument
+	if err := json.Unmarshal(out, &document); err != nil {
+		t.Fatalf("Failed to unmarshal JSON output: %v", err)
+	}
+
+	// Assert
+	if expected, actual := 3, document.Configuration.KeepDaily; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 367, document.Stats.Total; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 6, document.Stats.Keep; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 361, document.Stats.Prune; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := document.Stats.Total, len(document.Candidates); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+
+	first := document.Candidates[0]
+	if expected, actual := "2000-01-01T00-00-00Z", first.Name; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := path.Join(repoPath, first.Name), first.Path; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), first.Time; !actual.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := "keep", first.Operation; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := "prune", document.Candidates[1].Operation; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 1, len(document.Stats.OldestFallbacks); actual != expected {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := "yearly", document.Stats.OldestFallbacks[0].Rule; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestPruneStrict(t *testing.T) {
+	repoPath := t.TempDir()
+
+	createRepo(repoPath, t)
+
+	// Act
+	pruneArgs := []string{"--json", "-d", "3", "-m", "2", "-y", "1", "--strict", "yearly", repoPath}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("prune failed with %v", err)
+	}
+
+	var document JSONDocument
+	if err := json.Unmarshal(out, &document); err != nil {
+		t.Fatalf("Failed to unmarshal JSON output: %v", err)
+	}
+
+	// Assert
+	if expected, actual := 5, document.Stats.Keep; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := "prune", document.Candidates[0].Operation; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 0, len(document.Stats.OldestFallbacks); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestPrunePinned(t *testing.T) {
+	repoPath := t.TempDir()
+
+	createRepo(repoPath, t)
+
+	// Arrange
+	entries, err := os.ReadDir(repoPath)
+	if err != nil {
+		t.Fatalf("Failed to read repo: %v", err)
+	}
+	pinned := entries[0].Name()
+	if err := os.WriteFile(path.Join(repoPath, pinned, retention.DefaultPinMarker), []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create marker file: %v", err)
+	}
+
+	// Act
+	pruneArgs := []string{"--json", "-d", "3", "-m", "2", "-y", "1", "--strict", "yearly", repoPath}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("prune failed with %v", err)
+	}
+
+	var document JSONDocument
+	if err := json.Unmarshal(out, &document); err != nil {
+		t.Fatalf("Failed to unmarshal JSON output: %v", err)
+	}
+
+	// Assert
+	if expected, actual := 6, document.Stats.Keep; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	candidate := document.Candidates[0]
+	if expected, actual := pinned, candidate.Name; actual != expected {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+	if candidate.Reason == nil || candidate.Reason.Rule != retention.KeepReasonPinned {
+		t.Errorf("Expected %v to be pinned, got %v", pinned, candidate.Reason)
+	}
+}
+
+func TestPruneDelete(t *testing.T) {
+	repoPath := t.TempDir()
+
+	createRepo(repoPath, t)
+
+	// Act
+	pruneArgs := []string{"--delete", "-d", "3", "-m", "2", "-y", "1", repoPath}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("prune failed with %v: %s", err, out)
+	}
+
+	// Assert
+	files, err := os.ReadDir(repoPath)
+	if err != nil {
+		t.Errorf("Failed to read files in repo: %v", err)
+	}
+
+	if expected, actual := 6, len(files); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestPruneMoveTo(t *testing.T) {
+	repoPath := t.TempDir()
+	quarantine := path.Join(t.TempDir(), "quarantine")
+
+	createRepo(repoPath, t)
+
+	// Act
+	pruneArgs := []string{"--move-to", quarantine, "-d", "3", "-m", "2", "-y", "1", repoPath}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("prune failed with %v: %s", err, out)
+	}
+
+	// Assert
+	files, err := os.ReadDir(repoPath)
+	if err != nil {
+		t.Errorf("Failed to read files in repo: %v", err)
+	}
+	if expected, actual := 6, len(files); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+
+	moved, err := os.ReadDir(quarantine)
+	if err != nil {
+		t.Errorf("Failed to read files in quarantine: %v", err)
+	}
+	if expected, actual := 367-6, len(moved); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestPruneDeleteDryRun(t *testing.T) {
+	repoPath := t.TempDir()
+
+	createRepo(repoPath, t)
+
+	// Act
+	pruneArgs := []string{"--delete", "--dry-run", "-d", "3", "-m", "2", "-y", "1", repoPath}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("prune failed with %v", err)
+	}
+
+	// Assert
+	files, err := os.ReadDir(repoPath)
+	if err != nil {
+		t.Errorf("Failed to read files in repo: %v", err)
+	}
+
+	if expected, actual := 367, len(files); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 361, strings.Count(stderr.String(), "Would delete "); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestPruneMultipleDirectories(t *testing.T) {
+	oldRepoPath := t.TempDir()
+	newRepoPath := t.TempDir()
+
+	// Arrange
+	directories := map[string]bool{
+		path.Join(oldRepoPath, "2000-01-01"):           false,
+		path.Join(oldRepoPath, "2000-01-02"):           false,
+		path.Join(oldRepoPath, "2000-01-03"):           true,
+		path.Join(oldRepoPath, "2000-01-04"):           true,
+		path.Join(newRepoPath, "2000-01-05T00-00-00Z"): true,
+		path.Join(newRepoPath, "2000-01-06T00-00-00Z"): true,
+	}
+	for directory := range directories {
+		if err := os.Mkdir(directory, 0755); err != nil {
+			t.Fatalf("Failed to create directory %s: %v", directory, err)
+		}
+	}
+
+	// Act
+	pruneArgs := []string{"--json", "-d", "4", "--pattern", "%Y-%m-%d", "--pattern", "%Y-%m-%dT%H-%M-%S%z", oldRepoPath, newRepoPath}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("prune failed with %v", err)
+	}
+
+	var document JSONDocument
+	if err := json.Unmarshal(out, &document); err != nil {
+		t.Fatalf("Failed to unmarshal JSON output: %v", err)
+	}
+
+	// Assert
+	for _, candidate := range document.Candidates {
+		if expected, actual := directories[candidate.Path], candidate.Keep; actual != expected {
+			t.Errorf("%v: Got %v, expected %v", candidate.Path, actual, expected)
+		}
+	}
+
+	expectedSourceStats := []JSONSourceStats{
+		{Path: oldRepoPath, Total: 4, Keep: 2, Prune: 2},
+		{Path: newRepoPath, Total: 2, Keep: 2, Prune: 0},
+	}
+	if expected, actual := len(expectedSourceStats), len(document.Stats.Sources); actual != expected {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+	for i, expected := range expectedSourceStats {
+		if actual := document.Stats.Sources[i]; actual != expected {
+			t.Errorf("Expected %v, got %v", expected, actual)
+		}
+	}
+}
+
+func TestPruneInvalidNumberOfPatterns(t *testing.T) {
+	// Act
+	pruneArgs := []string{"-d", "4", "--pattern", "%Y-%m-%d", "--pattern", "%Y-%m-%d", "--pattern", "%Y-%m-%d", t.TempDir(), t.TempDir()}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.CombinedOutput()
+
+	// Assert
+	if err == nil {
+		t.Fatalf("Expected prune to fail")
+	}
+	// go run reports the exit code of prune, but exits with 1 itself
+	if expected := "exit status 2"; !strings.Contains(string(out), expected) {
+		t.Errorf("Expected output to contain %q, got %q", expected, out)
+	}
+}
+
+func TestPruneKeepWithinNow(t *testing.T) {
+	repoPath := t.TempDir()
+
+	createRepo(repoPath, t)
+
+	// Act
+	pruneArgs := []string{"--now", "2001-01-01T12:00:00Z", "--keep-within", "3d", repoPath}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("prune failed with %v", err)
+	}
+
+	// Assert
+	toPrune := strings.Fields(string(out))
+	if expected, actual := 367-3, len(toPrune); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	for _, name := range []string{"2000-12-30T00-00-00Z", "2000-12-31T00-00-00Z", "2001-01-01T00-00-00Z"} {
+		if strings.Contains(string(out), name) {
+			t.Errorf("Expected %v to be kept", name)
+		}
+	}
+}
+
+func TestPruneInvalidBackup(t *testing.T) {
+	repoPath := t.TempDir()
+
+	createRepo(repoPath, t)
+
+	// Arrange
+	// Simulate a backup that failed after creating its directory
+	incomplete := path.Join(repoPath, "2001-01-01T00-00-00Z")
+	if err := os.RemoveAll(incomplete); err != nil {
+		t.Fatalf("Failed to remove backup: %v", err)
+	}
+	if err := os.Mkdir(incomplete, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	// Act
+	pruneArgs := []string{"--json", "-d", "3", "--require-glob", "*.tar.gz", repoPath}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("prune failed with %v", err)
+	}
+
+	var document JSONDocument
+	if err := json.Unmarshal(out, &document); err != nil {
+		t.Fatalf("Failed to unmarshal JSON output: %v", err)
+	}
+
+	// Assert
+	// The incomplete backup is kept in addition to the 3 daily backups
+	if expected, actual := 4, document.Stats.Keep; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 1, len(document.Stats.Invalid); actual != expected {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := incomplete, document.Stats.Invalid[0].Path; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	for _, candidate := range document.Candidates {
+		if candidate.Name == "2000-12-29T00-00-00Z" && !candidate.Keep {
+			t.Errorf("Expected %v to be kept", candidate.Name)
+		}
+	}
+}
+
+func TestPruneVerifySHA256SumTestRepo(t *testing.T) {
+	repoPath := t.TempDir()
+
+	// The checksum files of the test repo do not contain valid checksums, so
+	// all backups but the ones fixed below are invalid
+	createRepo(repoPath, t)
+
+	// Arrange
+	for _, name := range []string{"2000-12-30T00-00-00Z", "2000-12-31T00-00-00Z", "2001-01-01T00-00-00Z"} {
+		backup := "backup-" + name + ".tar.gz"
+		content, err := os.ReadFile(path.Join(repoPath, name, backup))
+		if err != nil {
+			t.Fatalf("Failed to read backup: %v", err)
+		}
+		checksum := fmt.Sprintf("%x  %s\n", sha256.Sum256(content), backup)
+		if err := os.WriteFile(path.Join(repoPath, name, backup+".sha256sum"), []byte(checksum), 0644); err != nil {
+			t.Fatalf("Failed to write checksum: %v", err)
+		}
+	}
+
+	// Act
+	pruneArgs := []string{"--json", "--verify-sha256sum", "--keep-daily", "2", repoPath}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("prune failed with %v", err)
+	}
+
+	var document JSONDocument
+	if err := json.Unmarshal(out, &document); err != nil {
+		t.Fatalf("Failed to unmarshal JSON output: %v", err)
+	}
+
+	// Assert
+	if expected, actual := 364, len(document.Stats.Invalid); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	// Only the oldest valid backup is pruned, invalid ones do not take a keep slot
+	if expected, actual := 1, document.Stats.Prune; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	for _, candidate := range document.Candidates {
+		if candidate.Name == "2000-12-30T00-00-00Z" && candidate.Keep {
+			t.Errorf("Expected %v to be pruned", candidate.Name)
+		}
+	}
+}
+
+func TestPruneMinAge(t *testing.T) {
+	repoPath := t.TempDir()
+
+	createRepo(repoPath, t)
+
+	// Act
+	pruneArgs := []string{"--json", "--now", "2001-01-01T12:00:00Z", "--min-age", "5d", "-y", "1", repoPath}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("prune failed with %v", err)
+	}
+
+	var document JSONDocument
+	if err := json.Unmarshal(out, &document); err != nil {
+		t.Fatalf("Failed to unmarshal JSON output: %v", err)
+	}
+
+	// Assert
+	// The 5 backups younger than 5 days are not seen by the yearly rule, which
+	// keeps the newest older backup
+	if expected, actual := 6, document.Stats.Keep; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	for _, candidate := range document.Candidates {
+		if candidate.Name == "2000-12-28T00-00-00Z" && (candidate.Reason == nil || candidate.Reason.Rule != retention.KeepReasonTooYoung) {
+			t.Errorf("Expected %v to be too young, got %v", candidate.Name, candidate.Reason)
+		}
+		if candidate.Name == "2000-12-27T00-00-00Z" && (candidate.Reason == nil || candidate.Reason.Rule != "yearly") {
+			t.Errorf("Expected %v to be kept by the yearly rule, got %v", candidate.Name, candidate.Reason)
+		}
+	}
+}
+
+func TestPruneGuard(t *testing.T) {
+	repoPath := t.TempDir()
+
+	createRepo(repoPath, t)
+
+	testCases := []struct {
+		name string
+		args []string
+	}{
+		{"newest", []string{"-d", "0"}},
+		{"max prune percent", []string{"-d", "3", "--max-prune-percent", "50"}},
+		{"min keep", []string{"-d", "3", "--min-keep", "7"}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Act
+			args := append(append([]string{"run", "./"}, testCase.args...), "--delete", repoPath)
+			cmd := exec.Command("go", args...)
+			var stdout, stderr bytes.Buffer
+			cmd.Stdout = &stdout
+			cmd.Stderr = &stderr
+			err := cmd.Run()
+
+			// Assert
+			if err == nil {
+				t.Fatalf("Expected prune to fail")
+			}
+			// go run reports the exit code of prune, but exits with 1 itself
+			if expected := fmt.Sprintf("exit status %d", ExitCodeGuardFailed); !strings.Contains(stderr.String(), expected) {
+				t.Errorf("Expected %q in output, got %s", expected, stderr.String())
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("Expected no output, got %s", stdout.String())
+			}
+			if entries, _ := os.ReadDir(repoPath); len(entries) != 367 {
+				t.Errorf("Expected nothing to be deleted, got %v entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestPruneGuardForce(t *testing.T) {
+	repoPath := t.TempDir()
+
+	createRepo(repoPath, t)
+
+	// Act
+	pruneArgs := []string{"--force", "-d", "3", "--max-prune-percent", "50", repoPath}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.Output()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("prune failed with %v", err)
+	}
+	if expected, actual := 364, len(strings.Split(strings.TrimSpace(string(out)), "\n")); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestPruneGuardOldestPick(t *testing.T) {
+	repoPath := t.TempDir()
+
+	// Arrange
+	// December holds 31 daily backups, keeping the first of each month prunes
+	// the newest one
+	cmd := exec.Command("go", "run", "./cmd/test-repo", repoPath, "2000-01-01...2000-12-31")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("test-repo failed with %v: %s", err, out)
+	}
+
+	testCases := []struct {
+		name          string
+		args          []string
+		expectedError bool
+	}{
+		{"newest", []string{"-m", "3", "--monthly-pick", "first"}, true},
+		{"allow prune newest", []string{"-m", "3", "--monthly-pick", "first", "--allow-prune-newest"}, false},
+		{"allow prune newest min keep", []string{"-m", "3", "--monthly-pick", "first", "--allow-prune-newest", "--min-keep", "4"}, true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Act
+			args := append(append([]string{"run", "./"}, testCase.args...), repoPath)
+			cmd := exec.Command("go", args...)
+			var stdout, stderr bytes.Buffer
+			cmd.Stdout = &stdout
+			cmd.Stderr = &stderr
+			err := cmd.Run()
+
+			// Assert
+			if !testCase.expectedError {
+				if err != nil {
+					t.Fatalf("prune failed with %v: %s", err, stderr.String())
+				}
+				if expected, actual := 363, len(strings.Split(strings.TrimSpace(stdout.String()), "\n")); actual != expected {
+					t.Errorf("Expected %v, got %v", expected, actual)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected prune to fail")
+			}
+			if expected := fmt.Sprintf("exit status %d", ExitCodeGuardFailed); !strings.Contains(stderr.String(), expected) {
+				t.Errorf("Expected %q in output, got %s", expected, stderr.String())
+			}
+		})
+	}
+}
+
+func TestPruneInvalidNow(t *testing.T) {
+	// Act
+	pruneArgs := []string{"--now", "2001-01-01", "--keep-within", "3d", t.TempDir()}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.CombinedOutput()
+
+	// Assert
+	if err == nil {
+		t.Fatalf("Expected prune to fail")
+	}
+	if expected := "exit status 2"; !strings.Contains(string(out), expected) {
+		t.Errorf("Expected output to contain %q, got %q", expected, out)
+	}
+}
+
+func TestPruneJobFile(t *testing.T) {
+	dailyRepoPath := t.TempDir()
+	monthlyRepoPath := t.TempDir()
+
+	createRepo(dailyRepoPath, t)
+	createRepo(monthlyRepoPath, t)
+
+	jobFilePath := path.Join(t.TempDir(), "prune.yaml")
+	jobFile := fmt.Sprintf(`
+jobs:
+  - name: daily
+    sources:
+      - path: %s
+    keep-daily: 3
+  - name: monthly
+    sources:
+      - path: %s
+    keep-monthly: 2
+  - name: unused
+    sources:
+      - path: /does/not/exist
+    keep-yearly: 1
+`, dailyRepoPath, monthlyRepoPath)
+	if err := os.WriteFile(jobFilePath, []byte(jobFile), 0644); err != nil {
+		t.Fatalf("Failed to write job file: %v", err)
+	}
+
+	// Act
+	pruneArgs := []string{"--json", "--config", jobFilePath, "daily", "monthly"}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("prune failed with %v", err)
+	}
+
+	var document JSONJobsDocument
+	if err := json.Unmarshal(out, &document); err != nil {
+		t.Fatalf("Failed to unmarshal JSON output: %v", err)
+	}
+
+	// Assert
+	if expected, actual := 2, len(document.Jobs); actual != expected {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+	for i, expected := range []struct {
+		Name string
+		Keep int
+	}{{"daily", 3}, {"monthly", 2}} {
+		job := document.Jobs[i]
+		if actual := job.Name; actual != expected.Name {
+			t.Errorf("Expected %v, got %v", expected.Name, actual)
+		}
+		if actual := job.Stats.Keep; actual != expected.Keep {
+			t.Errorf("%v: Expected %v, got %v", job.Name, expected.Keep, actual)
+		}
+	}
+}
+
+func TestPruneFiles(t *testing.T) {
+	repoPath := t.TempDir()
+
+	// Arrange
+	files := map[string]bool{
+		"db-2000-01-01.sql.gz": false,
+		"db-2000-01-02.sql.gz": false,
+		"db-2000-01-03.sql.gz": true,
+		"db-2000-01-04.sql.gz": true,
+	}
+	for name := range files {
+		if err := os.WriteFile(path.Join(repoPath, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create file %s: %v", name, err)
+		}
+	}
+	// Directories are ignored when pruning files
+	if err := os.Mkdir(path.Join(repoPath, "db-1999-12-31.sql.gz"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	// Act
+	pruneArgs := []string{"--delete", "--type", "file", "--pattern", "db-%Y-%m-%d.sql.gz", "-d", "2", repoPath}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("prune failed with %v: %s", err, out)
+	}
+
+	// Assert
+	for name, expected := range files {
+		_, err := os.Stat(path.Join(repoPath, name))
+		if exists := err == nil; exists != expected {
+			t.Errorf("%q: Got exists %v, expected %v", name, exists, expected)
+		}
+	}
+	if _, err := os.Stat(path.Join(repoPath, "db-1999-12-31.sql.gz")); err != nil {
+		t.Errorf("Expected directory to be ignored, got %v", err)
+	}
+}
+
+func TestPruneSeries(t *testing.T) {
+	repoPath := t.TempDir()
+
+	// Arrange
+	directories := map[string]bool{
+		"host1-2000-01-01": false,
+		"host1-2000-01-02": true,
+		"host2-2000-01-01": true,
+		"unrelated":        true,
+	}
+	for name := range directories {
+		if err := os.Mkdir(path.Join(repoPath, name), 0755); err != nil {
+			t.Fatalf("Failed to create directory %s: %v", name, err)
+		}
+	}
+
+	// Act
+	pruneArgs := []string{"--json", "--delete", "-d", "1", "--regex", `^(?P<series>\w+)-(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})$`, repoPath}
+	args := append([]string{"run", "./"}, pruneArgs...)
+	cmd := exec.Command("go", args...)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("prune failed with %v", err)
+	}
+
+	var document JSONDocument
+	if err := json.Unmarshal(out, &document); err != nil {
+		t.Fatalf("Failed to unmarshal JSON output: %v", err)
+	}
+
+	// Assert
+	for name, expected := range directories {
+		_, err := os.Stat(path.Join(repoPath, name))
+		if exists := err == nil; exists != expected {
+			t.Errorf("%q: Got exists %v, expected %v", name, exists, expected)
+		}
+	}
+
+	expectedSeriesStats := []JSONSeriesStats{
+		{Name: "host1", Total: 2, Keep: 1, Prune: 1},
+		{Name: "host2", Total: 1, Keep: 1, Prune: 0},
+	}
+	if expected, actual := len(expectedSeriesStats), len(document.Stats.Series); actual != expected {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+	for i, expected := range expectedSeriesStats {
+		if actual := document.Stats.Series[i]; actual != expected {
+			t.Errorf("Expected %v, got %v", expected, actual)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/codezombiech/prune/retention"
+)
+
+// rename is used to move files/directories, replaceable for testing
+var rename = os.Rename
+
+// chtimes is used to record the time of the move, replaceable for testing
+var chtimes = os.Chtimes
+
+type MoveError struct {
+	Failed int
+	Total  int
+}
+
+func (e *MoveError) Error() string {
+	return fmt.Sprintf("failed to move %d of %d files/directories", e.Failed, e.Total)
+}
+
+// moveObjects moves all candidates to prune into the quarantine directory,
+// keeping their names. Moving continues if a single candidate cannot be moved
+// and a MoveError summarising all failures is returned at the end. The
+// modification time of moved files/directories is set to the time of the
+// move, so prune-purge is able to purge them by age
+func moveObjects(objects []retention.PruneCandidate, quarantine string, dryRun bool) error {
+	paths := make([]string, 0, len(objects))
+	for _, object := range objects {
+		paths = append(paths, object.Object.Path)
+	}
+
+	sort.Strings(paths)
+
+	if !dryRun && len(paths) > 0 {
+		if err := os.MkdirAll(quarantine, 0755); err != nil {
+			return err
+		}
+	}
+
+	// Targets assigned during this run, which do not exist yet in a dry run
+	assigned := make(map[string]bool)
+
+	failed := 0
+	for _, source := range paths {
+		target, err := quarantinePath(quarantine, source, assigned)
+		if err != nil {
+			errorLogger.Printf("Failed to move %s: %v", source, err)
+			failed++
+			continue
+		}
+
+		assigned[target] = true
+
+		if dryRun {
+			errorLogger.Printf("Would move %s to %s", source, target)
+			continue
+		}
+
+		if err := move(source, target); err != nil {
+			errorLogger.Printf("Failed to move %s to %s: %v", source, target, err)
+			failed++
+			continue
+		}
+
+		// Record the time of the move for prune-purge. A failure is a warning
+		// only, as the file/directory has been moved already
+		now := time.Now()
+		if err := chtimes(target, now, now); err != nil {
+			errorLogger.Printf("Warning: failed to set the time of the move of %s: %v", target, err)
+		}
+
+		if verbose {
+			errorLogger.Printf("Moved %s to %s", source, target)
+		}
+	}
+
+	if failed > 0 {
+		return &MoveError{Failed: failed, Total: len(paths)}
+	}
+
+	return nil
+}
+
+// quarantinePath returns the path inside quarantine named like source. If
+// the name is taken already by an existing or assigned target, e.g. by a
+// file/directory with the same name of another source, .1, .2 etc. is
+// appended
+func quarantinePath(quarantine string, source string, assigned map[string]bool) (string, error) {
+	name := path.Base(source)
+	target := path.Join(quarantine, name)
+	for i := 1; ; i++ {
+		if !assigned[target] {
+			if _, err := os.Lstat(target); errors.Is(err, fs.ErrNotExist) {
+				return target, nil
+			} else if err != nil {
+				return "", err
+			}
+		}
+		target = path.Join(quarantine, fmt.Sprintf("%s.%d", name, i))
+	}
+}
+
+// move renames source to target, falling back to copying and removing source
+// if they are on different file systems
+func move(source string, target string) error {
+	err := rename(source, target)
+	if errors.Is(err, syscall.EXDEV) {
+		if err := copyAll(source, target); err != nil {
+			os.RemoveAll(target)
+			return err
+		}
+		err = os.RemoveAll(source)
+	}
+	return err
+}
+
+// copyAll copies the file or directory tree source to target, preserving
+// permissions and symbolic links
+func copyAll(source string, target string) error {
+	return filepath.WalkDir(source, func(sourcePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(source, sourcePath)
+		if err != nil {
+			return err
+		}
+		targetPath := filepath.Join(target, relativePath)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case entry.IsDir():
+			return os.Mkdir(targetPath, info.Mode().Perm())
+		case entry.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(sourcePath)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, targetPath)
+		case entry.Type().IsRegular():
+			return copyFile(sourcePath, targetPath, info.Mode().Perm())
+		default:
+			return fmt.Errorf("cannot copy %s: unsupported file type %v", sourcePath, entry.Type())
+		}
+	})
+}
+
+func copyFile(sourcePath string, targetPath string, perm fs.FileMode) error {
+	sourceFile, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
+	targetFile, err := os.OpenFile(targetPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(targetFile, sourceFile); err != nil {
+		targetFile.Close()
+		return err
+	}
+	return targetFile.Close()
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/codezombiech/prune/retention"
+)
+
+func TestMoveObjects(t *testing.T) {
+	rootDir := t.TempDir()
+	quarantine := path.Join(rootDir, "quarantine")
+
+	// Arrange
+	// Two sources containing a directory with the same name
+	objects := []retention.PruneCandidate{}
+	for _, source := range []string{"old", "new"} {
+		directoryPath := path.Join(rootDir, source, "2000-01-01T00-00-00Z")
+		if err := os.MkdirAll(directoryPath, 0755); err != nil {
+			t.Fatalf("Failed to create directory %s", directoryPath)
+		}
+		if err := os.WriteFile(path.Join(directoryPath, "backup.tar.gz"), []byte(source), 0644); err != nil {
+			t.Fatalf("Failed to create file in %s", directoryPath)
+		}
+		objects = append(objects, retention.PruneCandidate{Object: retention.TimeStampedObject{Name: path.Base(directoryPath), Path: directoryPath}})
+	}
+	before := time.Now().Add(-time.Second)
+
+	// Act
+	err := moveObjects(objects, quarantine, false)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to move objects: %v", err)
+	}
+	for _, object := range objects {
+		if dirExists(object.Object.Path) {
+			t.Errorf("Expected %v to be moved", object.Object.Path)
+		}
+	}
+	// Sorted by path, so the directory of new is moved first
+	for name, expected := range map[string]string{"2000-01-01T00-00-00Z": "new", "2000-01-01T00-00-00Z.1": "old"} {
+		content, err := os.ReadFile(path.Join(quarantine, name, "backup.tar.gz"))
+		if err != nil {
+			t.Fatalf("Expected %v to be present in quarantine: %v", name, err)
+		}
+		if actual := string(content); actual != expected {
+			t.Errorf("%v: Expected %v, got %v", name, expected, actual)
+		}
+		info, err := os.Stat(path.Join(quarantine, name))
+		if err != nil {
+			t.Fatalf("Failed to stat %v: %v", name, err)
+		}
+		if info.ModTime().Before(before) {
+			t.Errorf("%v: Expected the time of the move, got %v", name, info.ModTime())
+		}
+	}
+}
+
+func TestMoveObjectsDryRun(t *testing.T) {
+	rootDir := t.TempDir()
+	quarantine := path.Join(rootDir, "quarantine")
+
+	// Arrange
+	directoryPath := path.Join(rootDir, "2000-01-01T00-00-00Z")
+	if err := os.Mkdir(directoryPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory %s", directoryPath)
+	}
+	objects := []retention.PruneCandidate{{Object: retention.TimeStampedObject{Path: directoryPath}}}
+
+	// Act
+	err := moveObjects(objects, quarantine, true)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to move objects: %v", err)
+	}
+	if !dirExists(directoryPath) {
+		t.Errorf("Expected %v to be present", directoryPath)
+	}
+	if dirExists(quarantine) {
+		t.Errorf("Expected %v not to be created", quarantine)
+	}
+}
+
+func TestMoveObjectsAcrossFileSystems(t *testing.T) {
+	rootDir := t.TempDir()
+	quarantine := path.Join(rootDir, "quarantine")
+
+	// Arrange
+	directoryPath := path.Join(rootDir, "2000-01-01T00-00-00Z")
+	if err := os.MkdirAll(path.Join(directoryPath, "data"), 0700); err != nil {
+		t.Fatalf("Failed to create directory %s", directoryPath)
+	}
+	if err := os.WriteFile(path.Join(directoryPath, "data", "backup.tar.gz"), []byte("backup"), 0600); err != nil {
+		t.Fatalf("Failed to create file in %s", directoryPath)
+	}
+	if err := os.Symlink("data/backup.tar.gz", path.Join(directoryPath, "latest")); err != nil {
+		t.Fatalf("Failed to create symlink in %s", directoryPath)
+	}
+	objects := []retention.PruneCandidate{{Object: retention.TimeStampedObject{Path: directoryPath}}}
+
+	rename = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+	t.Cleanup(func() { rename = os.Rename })
+
+	// Act
+	err := moveObjects(objects, quarantine, false)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to move objects: %v", err)
+	}
+	if dirExists(directoryPath) {
+		t.Errorf("Expected %v to be removed", directoryPath)
+	}
+	target := path.Join(quarantine, "2000-01-01T00-00-00Z")
+	content, err := os.ReadFile(path.Join(target, "latest"))
+	if err != nil {
+		t.Fatalf("Expected the symlink to be copied: %v", err)
+	}
+	if expected, actual := "backup", string(content); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	info, err := os.Stat(path.Join(target, "data", "backup.tar.gz"))
+	if err != nil {
+		t.Fatalf("Expected the file to be copied: %v", err)
+	}
+	if expected, actual := os.FileMode(0600), info.Mode().Perm(); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestMoveObjectsContinuesOnFailure(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	objects := []retention.PruneCandidate{
+		{Object: retention.TimeStampedObject{Path: "/foo/bar/a"}},
+		{Object: retention.TimeStampedObject{Path: "/foo/bar/b"}},
+		{Object: retention.TimeStampedObject{Path: "/foo/bar/c"}},
+	}
+
+	moved := []string{}
+	rename = func(oldpath, newpath string) error {
+		if oldpath == "/foo/bar/b" {
+			return os.ErrPermission
+		}
+		moved = append(moved, oldpath)
+		return os.Mkdir(newpath, 0755)
+	}
+	t.Cleanup(func() { rename = os.Rename })
+
+	// Act
+	err := moveObjects(objects, rootDir, false)
+
+	// Assert
+	var moveErr *MoveError
+	if !errors.As(err, &moveErr) {
+		t.Fatalf("Expected error of type MoveError, got %v", err)
+	}
+	if expected, actual := 1, moveErr.Failed; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if expected, actual := 2, len(moved); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestMoveObjectsChtimesFailure(t *testing.T) {
+	rootDir := t.TempDir()
+	quarantine := path.Join(rootDir, "quarantine")
+
+	// Arrange
+	directoryPath := path.Join(rootDir, "2000-01-01T00-00-00Z")
+	if err := os.Mkdir(directoryPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory %s", directoryPath)
+	}
+	objects := []retention.PruneCandidate{{Object: retention.TimeStampedObject{Path: directoryPath}}}
+
+	chtimes = func(name string, atime time.Time, mtime time.Time) error {
+		return os.ErrPermission
+	}
+	t.Cleanup(func() { chtimes = os.Chtimes })
+
+	// Act
+	err := moveObjects(objects, quarantine, false)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected the move to succeed, got %v", err)
+	}
+	if !dirExists(path.Join(quarantine, "2000-01-01T00-00-00Z")) {
+		t.Errorf("Expected %v to be moved", directoryPath)
+	}
+}
+
+func TestMoveObjectsDryRunCollisions(t *testing.T) {
+	rootDir := t.TempDir()
+	quarantine := path.Join(rootDir, "quarantine")
+
+	// Arrange
+	objects := []retention.PruneCandidate{
+		{Object: retention.TimeStampedObject{Path: path.Join(rootDir, "a", "x")}},
+		{Object: retention.TimeStampedObject{Path: path.Join(rootDir, "b", "x")}},
+	}
+
+	var output bytes.Buffer
+	errorLogger = log.New(&output, "", 0)
+	t.Cleanup(func() { errorLogger = log.New(os.Stderr, "", 0) })
+
+	// Act
+	err := moveObjects(objects, quarantine, true)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to move objects: %v", err)
+	}
+	for _, expected := range []string{
+		"Would move " + path.Join(rootDir, "a", "x") + " to " + path.Join(quarantine, "x") + "\n",
+		"Would move " + path.Join(rootDir, "b", "x") + " to " + path.Join(quarantine, "x.1") + "\n",
+	} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected %q in output, got %s", expected, output.String())
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package retention
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationRegex = regexp.MustCompile(`(\d+)([ymwdh])`)
+
+// Duration is a calendar based duration like 7d, 2w, 3m or 1y used by the
+// keep within rules. Units can be combined, e.g. 1y6m. Months and years are
+// calendar months and years (see time.AddDate). The zero value disables a
+// keep within rule
+type Duration struct {
+	Years  int
+	Months int
+	Weeks  int
+	Days   int
+	Hours  int
+}
+
+// ParseDuration parses a duration consisting of one or more numbers followed
+// by a unit: y (years), m (months), w (weeks), d (days) or h (hours)
+func ParseDuration(s string) (Duration, error) {
+	if s == "" {
+		return Duration{}, nil
+	}
+
+	matches := durationRegex.FindAllStringSubmatchIndex(s, -1)
+
+	d := Duration{}
+	end := 0
+	for _, match := range matches {
+		if match[0] != end {
+			break
+		}
+		end = match[1]
+
+		value, err := strconv.Atoi(s[match[2]:match[3]])
+		if err != nil {
+			return Duration{}, fmt.Errorf("invalid duration '%v': %w", s, err)
+		}
+		switch s[match[4]:match[5]] {
+		case "y":
+			d.Years += value
+		case "m":
+			d.Months += value
+		case "w":
+			d.Weeks += value
+		case "d":
+			d.Days += value
+		case "h":
+			d.Hours += value
+		}
+	}
+	if end != len(s) {
+		return Duration{}, fmt.Errorf("invalid duration '%v': expected numbers followed by y, m, w, d or h, e.g. 7d or 1y6m", s)
+	}
+
+	return d, nil
+}
+
+// IsZero reports whether d is the zero duration
+func (d Duration) IsZero() bool {
+	return d == Duration{}
+}
+
+// Before returns the time d before t
+func (d Duration) Before(t time.Time) time.Time {
+	return t.AddDate(-d.Years, -d.Months, -7*d.Weeks-d.Days).Add(time.Duration(-d.Hours) * time.Hour)
+}
+
+func (d Duration) String() string {
+	var b strings.Builder
+	for _, part := range []struct {
+		value int
+		unit  string
+	}{{d.Years, "y"}, {d.Months, "m"}, {d.Weeks, "w"}, {d.Days, "d"}, {d.Hours, "h"}} {
+		if part.value != 0 {
+			fmt.Fprintf(&b, "%d%s", part.value, part.unit)
+		}
+	}
+	return b.String()
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = duration
+	return nil
+}
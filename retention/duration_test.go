@@ -0,0 +1,64 @@
+package retention
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected Duration
+	}{
+		{"7d", Duration{Days: 7}},
+		{"2w", Duration{Weeks: 2}},
+		{"3m", Duration{Months: 3}},
+		{"1y", Duration{Years: 1}},
+		{"1y6m", Duration{Years: 1, Months: 6}},
+		{"12h", Duration{Hours: 12}},
+		{"", Duration{}},
+	}
+
+	for _, testCase := range testCases {
+		actual, err := ParseDuration(testCase.value)
+		if err != nil {
+			t.Errorf("%v: Failed to parse duration: %v", testCase.value, err)
+			continue
+		}
+		if actual != testCase.expected {
+			t.Errorf("%v: Got %+v, expected %+v", testCase.value, actual, testCase.expected)
+		}
+		if actual.String() != testCase.value {
+			t.Errorf("%v: Got %v, expected %v", testCase.value, actual.String(), testCase.value)
+		}
+	}
+}
+
+func TestParseDurationInvalid(t *testing.T) {
+	for _, value := range []string{"7", "d", "7x", "7d ", "-7d", "7d1"} {
+		if _, err := ParseDuration(value); err == nil {
+			t.Errorf("%v: expected error", value)
+		}
+	}
+}
+
+func TestDurationBefore(t *testing.T) {
+	reference := time.Date(2000, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		duration Duration
+		expected time.Time
+	}{
+		{Duration{Days: 7}, time.Date(2000, 3, 24, 12, 0, 0, 0, time.UTC)},
+		{Duration{Weeks: 2}, time.Date(2000, 3, 17, 12, 0, 0, 0, time.UTC)},
+		{Duration{Months: 1}, time.Date(2000, 3, 2, 12, 0, 0, 0, time.UTC)}, // 2000-02-31 normalized, see time.AddDate
+		{Duration{Years: 1}, time.Date(1999, 3, 31, 12, 0, 0, 0, time.UTC)},
+		{Duration{Hours: 13}, time.Date(2000, 3, 30, 23, 0, 0, 0, time.UTC)},
+	}
+
+	for _, testCase := range testCases {
+		if actual := testCase.duration.Before(reference); !actual.Equal(testCase.expected) {
+			t.Errorf("%v: Got %v, expected %v", testCase.duration, actual, testCase.expected)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package retention
+
+import (
+	"fmt"
+)
+
+// Pick selects which candidate of a bucket a bucket rule keeps
+type Pick int
+
+const (
+	PickNewest Pick = iota // Keep the newest candidate, e.g. the last backup of a month
+	PickOldest             // Keep the oldest candidate, e.g. the first backup of a month
+)
+
+var pickNames = map[Pick]string{
+	PickNewest: "newest",
+	PickOldest: "oldest",
+}
+
+// ParsePick parses the name of a pick: newest (or last) or oldest (or first)
+func ParsePick(s string) (Pick, error) {
+	switch s {
+	case "last":
+		return PickNewest, nil
+	case "first":
+		return PickOldest, nil
+	}
+	for pick, name := range pickNames {
+		if name == s {
+			return pick, nil
+		}
+	}
+	return PickNewest, fmt.Errorf("invalid pick '%v': expected newest, oldest, last or first", s)
+}
+
+func (p Pick) String() string {
+	return pickNames[p]
+}
+
+func (p Pick) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Pick) UnmarshalText(text []byte) error {
+	pick, err := ParsePick(string(text))
+	if err != nil {
+		return err
+	}
+	*p = pick
+	return nil
+}
+
+// candidate returns the candidate to keep of a bucket
+func (p Pick) candidate(candidates []*PruneCandidate) *PruneCandidate {
+	if p == PickOldest {
+		return getOldest(candidates)
+	}
+	return getNewest(candidates)
+}
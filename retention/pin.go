@@ -0,0 +1,63 @@
+package retention
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strings"
+)
+
+// DefaultPinMarker is the name of the marker file pinning the directory
+// containing it
+const DefaultPinMarker = ".prune-keep"
+
+// PinReasonList is the KeepReason.Bucket of files/directories pinned by a pin
+// list
+const PinReasonList = "pin list"
+
+// LoadPinList reads a pin list containing the name or path of a file/directory
+// to pin per line. Empty lines and lines starting with # are ignored
+func LoadPinList(pinListPath string) ([]string, error) {
+	file, err := os.Open(pinListPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	pins := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pins = append(pins, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return pins, nil
+}
+
+// pin sets TimeStampedObject.Pin of the objects being a directory containing
+// marker or having a name or path listed in pins
+func pin(objects []TimeStampedObject, marker string, pins []string) {
+	pinned := make(map[string]bool)
+	for _, p := range pins {
+		pinned[path.Clean(p)] = true
+	}
+
+	for i := range objects {
+		object := &objects[i]
+		if pinned[object.Name] || pinned[path.Clean(object.Path)] {
+			object.Pin = PinReasonList
+			continue
+		}
+		if marker != "" && object.IsDir {
+			if _, err := os.Stat(path.Join(object.Path, marker)); err == nil {
+				object.Pin = marker
+			}
+		}
+	}
+}
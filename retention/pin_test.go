@@ -0,0 +1,105 @@
+package retention
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestGetObjectsPinned(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	for _, name := range []string{"2000-01-01", "2000-01-02", "2000-01-03"} {
+		if err := os.Mkdir(path.Join(rootDir, name), 0755); err != nil {
+			t.Fatalf("Failed to create directory %s", name)
+		}
+	}
+	if err := os.WriteFile(path.Join(rootDir, "2000-01-04"), []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create file 2000-01-04")
+	}
+	if err := os.WriteFile(path.Join(rootDir, "2000-01-01", DefaultPinMarker), []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create marker file")
+	}
+
+	// Act
+	traverser := FileSystemTraverser{Pattern: PatternISO8601DateOnly, Type: EntryTypeAny, PinMarker: DefaultPinMarker, Pins: []string{"2000-01-03", path.Join(rootDir, "2000-01-04")}}
+	objects, err := traverser.GetObjects(rootDir)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to get objects for path %s: %v", rootDir, err)
+	}
+
+	expectedPins := map[string]string{
+		"2000-01-01": DefaultPinMarker,
+		"2000-01-02": "",
+		"2000-01-03": PinReasonList,
+		"2000-01-04": PinReasonList,
+	}
+	objectsMap := toObjectsMap(objects)
+	for name, expected := range expectedPins {
+		object, ok := objectsMap[path.Join(rootDir, name)]
+		if !ok {
+			t.Fatalf("Expected %v to be present", name)
+		}
+		if actual := object.Pin; expected != actual {
+			t.Errorf("%v: Expected %v, got %v", name, expected, actual)
+		}
+	}
+}
+
+func TestGetObjectsPinMarkerDisabled(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	if err := os.MkdirAll(path.Join(rootDir, "2000-01-01", DefaultPinMarker), 0755); err != nil {
+		t.Fatalf("Failed to create directory 2000-01-01")
+	}
+
+	// Act
+	traverser := FileSystemTraverser{Pattern: PatternISO8601DateOnly}
+	objects, err := traverser.GetObjects(rootDir)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to get objects for path %s: %v", rootDir, err)
+	}
+	if expected, actual := 1, len(objects); expected != actual {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+	if actual := objects[0].Pin; actual != "" {
+		t.Errorf("Expected no pin, got %v", actual)
+	}
+}
+
+func TestLoadPinList(t *testing.T) {
+	// Arrange
+	pinList := path.Join(t.TempDir(), "pins")
+	content := "# Release backups\n2000-01-01\n\n  /backups/2000-02-01  \n"
+	if err := os.WriteFile(pinList, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create pin list: %v", err)
+	}
+
+	// Act
+	pins, err := LoadPinList(pinList)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to load pin list: %v", err)
+	}
+	if expected := []string{"2000-01-01", "/backups/2000-02-01"}; !reflect.DeepEqual(expected, pins) {
+		t.Errorf("Expected %v, got %v", expected, pins)
+	}
+}
+
+func TestLoadPinListMissing(t *testing.T) {
+	// Act
+	_, err := LoadPinList(path.Join(t.TempDir(), "missing"))
+
+	// Assert
+	if err == nil {
+		t.Errorf("Expected an error for a missing pin list")
+	}
+}
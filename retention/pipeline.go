@@ -0,0 +1,168 @@
+package retention
+
+import (
+	"fmt"
+)
+
+// RuleFactory creates the rule defined by a Configuration, nil if the
+// Configuration disables the rule
+type RuleFactory func(c Configuration) Rule
+
+type registeredRule struct {
+	name    string
+	factory RuleFactory
+}
+
+// RuleRegistry defines the rules and the order they are applied in. Rules
+// only keep candidates not kept by a rule applied before them, so the order
+// matters
+type RuleRegistry struct {
+	rules []registeredRule
+}
+
+// DefaultRuleRegistry is the registry used by NewPrune
+var DefaultRuleRegistry = NewRuleRegistry()
+
+// NewRuleRegistry creates a registry containing the built-in rules in the
+// order last, within, within-hourly/daily/weekly/monthly/yearly, hourly,
+// daily, weekly, monthly, quarterly, half-yearly and yearly
+func NewRuleRegistry() *RuleRegistry {
+	return &RuleRegistry{rules: []registeredRule{
+		{"last", func(c Configuration) Rule {
+			if c.KeepLast > NoPrune {
+				return &KeepLastRule{KeepCount: c.KeepLast}
+			}
+			return nil
+		}},
+		{"within", func(c Configuration) Rule {
+			if !c.KeepWithin.IsZero() {
+				return &KeepWithinRule{Within: c.KeepWithin, Now: c.Now}
+			}
+			return nil
+		}},
+		{"within-hourly", func(c Configuration) Rule {
+			return keepWithinBucketRule(NewKeepWithinHourlyRule(c.KeepWithinHourly, c.Now), c.HourlyPick)
+		}},
+		{"within-daily", func(c Configuration) Rule {
+			return keepWithinBucketRule(NewKeepWithinDailyRule(c.KeepWithinDaily, c.Now), c.DailyPick)
+		}},
+		{"within-weekly", func(c Configuration) Rule {
+			return keepWithinBucketRule(NewKeepWithinWeeklyRule(c.KeepWithinWeekly, c.Now, c.WeekStart), c.WeeklyPick)
+		}},
+		{"within-monthly", func(c Configuration) Rule {
+			return keepWithinBucketRule(NewKeepWithinMonthlyRule(c.KeepWithinMonthly, c.Now), c.MonthlyPick)
+		}},
+		{"within-yearly", func(c Configuration) Rule {
+			return keepWithinBucketRule(NewKeepWithinYearlyRule(c.KeepWithinYearly, c.Now), c.YearlyPick)
+		}},
+		{"hourly", func(c Configuration) Rule {
+			if c.KeepHourly > NoPrune {
+				return &KeepHourlyRule{KeepCount: c.KeepHourly, Pick: c.HourlyPick, Strict: c.isStrict("hourly")}
+			}
+			return nil
+		}},
+		{"daily", func(c Configuration) Rule {
+			if c.KeepDaily > NoPrune {
+				return &KeepDailyRule{KeepCount: c.KeepDaily, Pick: c.DailyPick, Strict: c.isStrict("daily")}
+			}
+			return nil
+		}},
+		{"weekly", func(c Configuration) Rule {
+			if c.KeepWeekly > NoPrune {
+				return &KeepWeeklyRule{KeepCount: c.KeepWeekly, Pick: c.WeeklyPick, Strict: c.isStrict("weekly"), WeekStart: c.WeekStart}
+			}
+			return nil
+		}},
+		{"monthly", func(c Configuration) Rule {
+			if c.KeepMonthly > NoPrune {
+				return &KeepMonthlyRule{KeepCount: c.KeepMonthly, Pick: c.MonthlyPick, Strict: c.isStrict("monthly")}
+			}
+			return nil
+		}},
+		{"quarterly", func(c Configuration) Rule {
+			if c.KeepQuarterly > NoPrune {
+				return &KeepQuarterlyRule{KeepCount: c.KeepQuarterly, Pick: c.QuarterlyPick, Strict: c.isStrict("quarterly")}
+			}
+			return nil
+		}},
+		{"half-yearly", func(c Configuration) Rule {
+			if c.KeepHalfYearly > NoPrune {
+				return &KeepHalfYearlyRule{KeepCount: c.KeepHalfYearly, Pick: c.HalfYearlyPick, Strict: c.isStrict("half-yearly")}
+			}
+			return nil
+		}},
+		{"yearly", func(c Configuration) Rule {
+			if c.KeepYearly > NoPrune {
+				return &KeepYearlyRule{KeepCount: c.KeepYearly, Pick: c.YearlyPick, Strict: c.isStrict("yearly")}
+			}
+			return nil
+		}},
+	}}
+}
+
+func keepWithinBucketRule(rule KeepWithinBucketRule, pick Pick) Rule {
+	if rule.Within.IsZero() {
+		return nil
+	}
+	rule.Pick = pick
+	return &rule
+}
+
+// RegisterRule adds a custom rule to the DefaultRuleRegistry, see
+// RuleRegistry.Register
+func RegisterRule(name string, factory RuleFactory) error {
+	return DefaultRuleRegistry.Register(name, factory)
+}
+
+// Register adds a custom rule applied after all rules registered before
+func (r *RuleRegistry) Register(name string, factory RuleFactory) error {
+	if r.index(name) >= 0 {
+		return fmt.Errorf("rule %v already registered", name)
+	}
+	r.rules = append(r.rules, registeredRule{name: name, factory: factory})
+	return nil
+}
+
+// RegisterBefore adds a custom rule applied right before the rule named
+// before, e.g. before "hourly" to apply it before all bucket rules
+func (r *RuleRegistry) RegisterBefore(name string, before string, factory RuleFactory) error {
+	if r.index(name) >= 0 {
+		return fmt.Errorf("rule %v already registered", name)
+	}
+	i := r.index(before)
+	if i < 0 {
+		return fmt.Errorf("rule %v not registered", before)
+	}
+	r.rules = append(r.rules[:i], append([]registeredRule{{name: name, factory: factory}}, r.rules[i:]...)...)
+	return nil
+}
+
+// Names returns the names of the registered rules in the order they are
+// applied
+func (r *RuleRegistry) Names() []string {
+	names := make([]string, 0, len(r.rules))
+	for _, rule := range r.rules {
+		names = append(names, rule.name)
+	}
+	return names
+}
+
+// Pipeline returns the rules enabled by c in the order they are applied
+func (r *RuleRegistry) Pipeline(c Configuration) []Rule {
+	pipeline := []Rule{}
+	for _, rule := range r.rules {
+		if instance := rule.factory(c); instance != nil {
+			pipeline = append(pipeline, instance)
+		}
+	}
+	return pipeline
+}
+
+func (r *RuleRegistry) index(name string) int {
+	for i, rule := range r.rules {
+		if rule.name == name {
+			return i
+		}
+	}
+	return -1
+}
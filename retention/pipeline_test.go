@@ -0,0 +1,160 @@
+package retention
+
+import (
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRuleRegistryNames(t *testing.T) {
+	// Act
+	names := NewRuleRegistry().Names()
+
+	// Assert
+	expected := []string{"last", "within", "within-hourly", "within-daily", "within-weekly", "within-monthly", "within-yearly", "hourly", "daily", "weekly", "monthly", "quarterly", "half-yearly", "yearly"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Got %v, expected %v", names, expected)
+	}
+}
+
+func TestRuleRegistryPipeline(t *testing.T) {
+	// Arrange
+	config := Configuration{
+		KeepLast: 1, KeepHourly: 1, KeepDaily: 1, KeepWeekly: 1, KeepMonthly: 1, KeepQuarterly: 1, KeepHalfYearly: 1, KeepYearly: 1,
+		KeepWithin: Duration{Days: 1}, KeepWithinHourly: Duration{Days: 1}, KeepWithinDaily: Duration{Days: 1}, KeepWithinWeekly: Duration{Days: 1}, KeepWithinMonthly: Duration{Days: 1}, KeepWithinYearly: Duration{Days: 1},
+	}
+
+	// Act
+	pipeline := NewRuleRegistry().Pipeline(config)
+
+	// Assert
+	// The order of the hard-coded rules before the pipeline was introduced
+	expected := []string{"KeepLastRule", "KeepWithinRule", "within-hourly", "within-daily", "within-weekly", "within-monthly", "within-yearly", "KeepHourlyRule", "KeepDailyRule", "KeepWeeklyRule", "KeepMonthlyRule", "KeepQuarterlyRule", "KeepHalfYearlyRule", "KeepYearlyRule"}
+	actual := []string{}
+	for _, rule := range pipeline {
+		if withinRule, ok := rule.(*KeepWithinBucketRule); ok {
+			actual = append(actual, "within-"+withinRule.Name)
+		} else {
+			actual = append(actual, reflect.TypeOf(rule).Elem().Name())
+		}
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Got %v, expected %v", actual, expected)
+	}
+}
+
+func TestRuleRegistryPipelineDisabledRules(t *testing.T) {
+	// Arrange
+	config := Configuration{KeepLast: NoPrune, KeepHourly: NoPrune, KeepDaily: 7, KeepWeekly: NoPrune, KeepMonthly: NoPrune, KeepQuarterly: NoPrune, KeepHalfYearly: NoPrune, KeepYearly: NoPrune}
+
+	// Act
+	pipeline := NewRuleRegistry().Pipeline(config)
+
+	// Assert
+	if expected, actual := 1, len(pipeline); actual != expected {
+		t.Fatalf("Got %v, expected %v", actual, expected)
+	}
+	if rule, ok := pipeline[0].(*KeepDailyRule); !ok || rule.KeepCount != 7 {
+		t.Errorf("Got %#v, expected daily rule keeping 7", pipeline[0])
+	}
+}
+
+func TestRuleRegistryPipelineZeroKeepCounts(t *testing.T) {
+	// Arrange
+	// A zero keep count is a rule keeping nothing for every rule
+	config := Configuration{}
+
+	// Act
+	pipeline := NewRuleRegistry().Pipeline(config)
+
+	// Assert
+	if expected, actual := 8, len(pipeline); actual != expected {
+		t.Errorf("Got %v, expected %v", actual, expected)
+	}
+}
+
+// keepNameRule keeps all candidates with a name containing a substring
+type keepNameRule struct {
+	substring string
+}
+
+func (r *keepNameRule) Apply(objects []PruneCandidate) {
+	for i := range objects {
+		object := &objects[i]
+		if !object.Keep && strings.Contains(object.Object.Name, r.substring) {
+			object.Keep = true
+			object.Reason = &KeepReason{Rule: "name", Number: 1, Bucket: r.substring}
+		}
+	}
+}
+
+func TestRuleRegistryCustomRule(t *testing.T) {
+	// Arrange
+	registry := NewRuleRegistry()
+	err := registry.RegisterBefore("name", "hourly", func(c Configuration) Rule {
+		return &keepNameRule{substring: "T12"}
+	})
+	if err != nil {
+		t.Fatalf("Failed to register rule: %v", err)
+	}
+	config := Configuration{Sources: testSources, KeepDaily: 2}
+	testDirectories := []struct {
+		Name           string
+		ExpectedReason string
+	}{
+		{"2000-01-01T00-00-00Z", "daily #2, 2000-01-01"},
+		{"2000-01-02T00-00-00Z", ""},
+		{"2000-01-02T12-00-00Z", "name #1, T12"},
+		{"2000-01-03T00-00-00Z", "daily #1, 2000-01-03"},
+	}
+	testObjects := []TestObject{}
+	for _, v := range testDirectories {
+		testObjects = append(testObjects, TestObject{v.Name, v.ExpectedReason != ""})
+	}
+	entries := createEntries(testObjects, t)
+
+	// Act
+	prune := NewPruneWithRegistry(config, registry)
+	pruneResult, err := prune.Calculate(entries)
+	if err != nil {
+		t.Fatalf("Failed to calculate directories to prune: %s", err)
+	}
+
+	// Assert
+	assertResultMatchesTestObjects(testObjects, pruneResult, t)
+
+	for _, v := range testDirectories {
+		object := pruneResult.Objects[path.Join(testBaseDirectory, v.Name)]
+
+		var actual string
+		if object.Reason != nil {
+			actual = object.Reason.String()
+		}
+		if actual != v.ExpectedReason {
+			t.Errorf("%v: Got reason %q, expected %q", v.Name, actual, v.ExpectedReason)
+		}
+	}
+
+	if expected, actual := "name", registry.Names()[7]; actual != expected {
+		t.Errorf("Got %v, expected %v", actual, expected)
+	}
+}
+
+func TestRuleRegistryRegisterInvalid(t *testing.T) {
+	registry := NewRuleRegistry()
+	factory := func(c Configuration) Rule { return nil }
+
+	if err := registry.Register("daily", factory); err == nil {
+		t.Errorf("Expected error for duplicate rule")
+	}
+	if err := registry.RegisterBefore("custom", "secondly", factory); err == nil {
+		t.Errorf("Expected error for unknown rule")
+	}
+	if err := registry.Register("custom", factory); err != nil {
+		t.Errorf("Failed to register rule: %v", err)
+	}
+	if expected, actual := "custom", registry.Names()[len(registry.Names())-1]; actual != expected {
+		t.Errorf("Got %v, expected %v", actual, expected)
+	}
+}
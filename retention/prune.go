@@ -0,0 +1,341 @@
+// Package retention calculates which timestamped files/directories to keep and
+// which to prune based on a retention policy (e.g. keep 7 daily, 4 weekly and
+// 12 monthly backups).
+//
+// Typical usage is to retrieve the timestamped directories using a
+// FileSystemTraverser and pass them to Prune.Calculate:
+//
+//	traverser := retention.FileSystemTraverser{Pattern: retention.PatternAlmostISO8601DateAndTime}
+//	directories, err := traverser.GetObjects("/backups")
+//	...
+//	prune := retention.NewPrune(retention.Configuration{Sources: []retention.Source{{Path: "/backups"}}, KeepDaily: 7, ...})
+//	result, err := prune.Calculate(directories)
+package retention
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NoPrune disables a keep rule
+const NoPrune = -1
+
+// Source is a directory containing timestamped files/directories. The
+// files/directories of all sources of a Configuration form a single set the
+// retention policy is applied to
+type Source struct {
+	Path           string    `json:"path"`
+	Pattern        string    `json:"pattern"`
+	Regex          string    `json:"regex,omitempty"`
+	SeriesPrefixes []string  `json:"seriesPrefixes,omitempty" yaml:"series-prefixes"`
+	Type           EntryType `json:"type"`
+	// DefaultTimezone is the IANA time zone name (or "Local") of timestamps
+	// without time zone, UTC if empty
+	DefaultTimezone string `json:"defaultTimezone,omitempty" yaml:"default-timezone"`
+}
+
+// Configuration defines the retention policy. Set a keep count to NoPrune to
+// disable the corresponding rule
+type Configuration struct {
+	Sources     []Source `json:"sources"`
+	KeepLast    int      `json:"keepLast"`
+	KeepHourly  int      `json:"keepHourly"`
+	KeepDaily   int      `json:"keepDaily"`
+	KeepWeekly  int      `json:"keepWeekly"`
+	KeepMonthly int      `json:"keepMonthly"`
+	KeepYearly  int      `json:"keepYearly"`
+
+	KeepQuarterly  int       `json:"keepQuarterly"`
+	KeepHalfYearly int       `json:"keepHalfYearly"`
+	WeekStart      WeekStart `json:"weekStart"` // First day of the weeks of the weekly rules
+
+	// Candidate of each bucket kept by the bucket rules (including the keep
+	// within rules) of a period
+	HourlyPick     Pick `json:"hourlyPick"`
+	DailyPick      Pick `json:"dailyPick"`
+	WeeklyPick     Pick `json:"weeklyPick"`
+	MonthlyPick    Pick `json:"monthlyPick"`
+	QuarterlyPick  Pick `json:"quarterlyPick"`
+	HalfYearlyPick Pick `json:"halfYearlyPick"`
+	YearlyPick     Pick `json:"yearlyPick"`
+
+	// Strict lists the names of the rules (e.g. monthly or StrictAll) not
+	// keeping the oldest candidate if their keep count cannot be satisfied
+	// otherwise
+	Strict []string `json:"strict,omitempty"`
+
+	// Keep within rules are disabled if zero
+	KeepWithin        Duration `json:"keepWithin"`
+	KeepWithinHourly  Duration `json:"keepWithinHourly"`
+	KeepWithinDaily   Duration `json:"keepWithinDaily"`
+	KeepWithinWeekly  Duration `json:"keepWithinWeekly"`
+	KeepWithinMonthly Duration `json:"keepWithinMonthly"`
+	KeepWithinYearly  Duration `json:"keepWithinYearly"`
+
+	// MinAge keeps all candidates younger than MinAge before Now and excludes
+	// them from the rules. Disabled if zero
+	MinAge Duration `json:"minAge"`
+
+	// Now is the reference time of age based rules like the keep within
+	// rules. NewPrune uses time.Now if zero
+	Now time.Time `json:"now"`
+
+	// Timezone is the IANA time zone name (or "Local") all timestamps are
+	// converted to before applying the rules, e.g. to define the days of the
+	// daily rule. Timestamps are used as parsed if empty
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// StrictAll disables keeping the oldest candidate for all rules, see
+// Configuration.Strict
+const StrictAll = "all"
+
+// strictRuleNames are the names of the rules supporting Configuration.Strict
+var strictRuleNames = []string{"hourly", "daily", "weekly", "monthly", "quarterly", "half-yearly", "yearly"}
+
+// ValidateStrict verifies names only contains StrictAll or names of rules
+// keeping the oldest candidate if their keep count cannot be satisfied
+func ValidateStrict(names []string) error {
+	for _, name := range names {
+		if !isStrictRuleName(name) {
+			return fmt.Errorf("invalid strict rule '%v': expected %v or %v", name, strings.Join(strictRuleNames, ", "), StrictAll)
+		}
+	}
+	return nil
+}
+
+func isStrictRuleName(name string) bool {
+	if name == StrictAll {
+		return true
+	}
+	for _, ruleName := range strictRuleNames {
+		if name == ruleName {
+			return true
+		}
+	}
+	return false
+}
+
+// isStrict reports whether the rule must not keep the oldest candidate
+func (c *Configuration) isStrict(rule string) bool {
+	for _, name := range c.Strict {
+		if name == rule || name == StrictAll {
+			return true
+		}
+	}
+	return false
+}
+
+// NewConfiguration creates a Configuration with the given keep counts. The
+// quarterly and half-yearly rules are disabled, set KeepQuarterly and
+// KeepHalfYearly to enable them
+func NewConfiguration(sources []Source, keepLast int, keepHourly int, keepDaily int, keepWeekly int, keepMonthly int, keepYearly int) Configuration {
+	return Configuration{Sources: sources, KeepLast: keepLast, KeepHourly: keepHourly, KeepDaily: keepDaily, KeepWeekly: keepWeekly, KeepMonthly: keepMonthly, KeepQuarterly: NoPrune, KeepHalfYearly: NoPrune, KeepYearly: keepYearly}
+}
+
+type Prune struct {
+	config   Configuration
+	registry *RuleRegistry
+}
+
+// NewPrune creates a Prune for the given retention policy using the rules of
+// the DefaultRuleRegistry
+func NewPrune(c Configuration) Prune {
+	return NewPruneWithRegistry(c, DefaultRuleRegistry)
+}
+
+// NewPruneWithRegistry creates a Prune for the given retention policy using
+// the rules of registry
+func NewPruneWithRegistry(c Configuration, registry *RuleRegistry) Prune {
+	if c.Now.IsZero() {
+		c.Now = time.Now()
+	}
+	return Prune{config: c, registry: registry}
+}
+
+// Calculate applies the retention policy to the given directories and returns
+// which of them to keep and which to prune
+func (p *Prune) Calculate(directories []TimeStampedObject) (PruneResult, error) {
+	// Return immediately if empty set of directories
+	if len(directories) == 0 {
+		return PruneResult{Objects: make(map[string]*PruneCandidate), ToKeep: []PruneCandidate{}, ToPrune: []PruneCandidate{}}, nil
+	}
+
+	location, err := p.location()
+	if err != nil {
+		return PruneResult{}, err
+	}
+
+	// Candidates too young to be pruned, e.g. backups still being written
+	var cutoff time.Time
+	if !p.config.MinAge.IsZero() {
+		cutoff = p.config.MinAge.Before(p.config.Now)
+	}
+
+	// Copy to new struct with keep flag
+	objects := make([]PruneCandidate, 0, len(directories))
+	for _, directory := range directories {
+		if location != nil {
+			directory.Time = directory.Time.In(location)
+		}
+		object := PruneCandidate{Object: directory}
+		if directory.Invalid != "" {
+			object.Keep = true
+			object.Reason = &KeepReason{Rule: KeepReasonInvalid, Bucket: directory.Invalid}
+		}
+		if !cutoff.IsZero() && directory.Time.After(cutoff) {
+			object.Keep = true
+			object.Reason = &KeepReason{Rule: KeepReasonTooYoung}
+		}
+		if directory.Pin != "" {
+			object.Keep = true
+			object.Reason = &KeepReason{Rule: KeepReasonPinned, Bucket: directory.Pin}
+		}
+		objects = append(objects, object)
+	}
+
+	if pipeline := p.registry.Pipeline(p.config); len(pipeline) > 0 {
+		// Apply the rules to each series independently
+		for _, series := range splitBySeries(objects) {
+			// Pinned, invalid and too young candidates are kept anyway and
+			// must not consume keep counts
+			ruleCandidates, _ := splitExcluded(series)
+			if len(ruleCandidates) == 0 {
+				continue
+			}
+			for _, rule := range pipeline {
+				rule.Apply(ruleCandidates)
+			}
+		}
+	} else {
+		// Nothing to prune, set the keep flag on all objects
+		for i := 0; i < len(objects); i++ {
+			object := &objects[i]
+			object.Keep = true
+		}
+	}
+
+	keep, prune := filterTimeStampedObjectByKeep(objects)
+
+	objectsMap := make(map[string]*PruneCandidate)
+	for i := 0; i < len(objects); i++ {
+		object := &objects[i]
+		objectsMap[object.Object.Path] = object
+	}
+
+	result := PruneResult{Objects: objectsMap, ToKeep: keep, ToPrune: prune}
+
+	return result, nil
+}
+
+// excluded reports whether the candidate is excluded from the rules
+func (c *PruneCandidate) excluded() bool {
+	return c.Object.Pin != "" || c.Object.Invalid != "" || (c.Reason != nil && c.Reason.Rule == KeepReasonTooYoung)
+}
+
+// splitExcluded sorts objects so candidates excluded from the rules (pinned,
+// invalid or too young) come last and returns the sub-slices of the remaining and the
+// excluded candidates. The sub-slices share the backing array of objects
+func splitExcluded(objects []PruneCandidate) ([]PruneCandidate, []PruneCandidate) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return !objects[i].excluded() && objects[j].excluded()
+	})
+
+	i := 0
+	for i < len(objects) && !objects[i].excluded() {
+		i++
+	}
+	return objects[:i], objects[i:]
+}
+
+// location returns the location of Configuration.Timezone, nil if not set
+func (p *Prune) location() (*time.Location, error) {
+	if p.config.Timezone == "" {
+		return nil, nil
+	}
+	location, err := time.LoadLocation(p.config.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone '%v': %w", p.config.Timezone, err)
+	}
+	return location, nil
+}
+
+func filterTimeStampedObjectByKeep(objects []PruneCandidate) ([]PruneCandidate, []PruneCandidate) {
+	keep := make([]PruneCandidate, 0, len(objects))
+	prune := make([]PruneCandidate, 0, len(objects))
+
+	for _, object := range objects {
+		if object.Keep {
+			keep = append(keep, object)
+		} else {
+			prune = append(prune, object)
+		}
+	}
+
+	return keep, prune
+}
+
+type PruneCandidate struct {
+	Object TimeStampedObject
+	Keep   bool
+	Reason *KeepReason // Rule that kept the candidate, nil if not kept by a rule
+}
+
+type Day struct {
+	Year  int
+	Month time.Month
+	Day   int
+	Time  time.Time
+}
+
+type PruneResult struct {
+	Objects map[string]*PruneCandidate
+	ToKeep  []PruneCandidate
+	ToPrune []PruneCandidate
+}
+
+// OldestFallbacks returns the candidates, sorted by path, kept because a rule
+// could not satisfy its keep count otherwise (see KeepReason.Oldest)
+func (r *PruneResult) OldestFallbacks() []PruneCandidate {
+	fallbacks := []PruneCandidate{}
+	for _, object := range r.ToKeep {
+		if object.Reason != nil && object.Reason.Oldest {
+			fallbacks = append(fallbacks, object)
+		}
+	}
+	sort.Slice(fallbacks, func(i, j int) bool {
+		return fallbacks[i].Object.Path < fallbacks[j].Object.Path
+	})
+	return fallbacks
+}
+
+// Invalid returns the candidates, sorted by path, failing a validity check
+// (see ValidityCheck)
+func (r *PruneResult) Invalid() []PruneCandidate {
+	invalid := []PruneCandidate{}
+	for _, object := range r.ToKeep {
+		if object.Object.Invalid != "" {
+			invalid = append(invalid, object)
+		}
+	}
+	sort.Slice(invalid, func(i, j int) bool {
+		return invalid[i].Object.Path < invalid[j].Object.Path
+	})
+	return invalid
+}
+
+// Series returns the sorted names of all series of the result
+func (r *PruneResult) Series() []string {
+	seen := make(map[string]bool)
+	series := []string{}
+	for _, object := range r.Objects {
+		if !seen[object.Object.Series] {
+			seen[object.Object.Series] = true
+			series = append(series, object.Object.Series)
+		}
+	}
+	sort.Strings(series)
+	return series
+}
@@ -0,0 +1,207 @@
+package retention
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itchyny/timefmt-go"
+)
+
+// Names of the capture groups used by RegexTimeParser
+const (
+	RegexGroupTimestamp = "ts"
+	RegexGroupYear      = "year"
+	RegexGroupMonth     = "month"
+	RegexGroupDay       = "day"
+	RegexGroupHour      = "hour"
+	RegexGroupMinute    = "minute"
+	RegexGroupSecond    = "second"
+	RegexGroupTimeZone  = "tz"
+)
+
+// TimeParser parses the timestamp from the name of a file/directory
+type TimeParser interface {
+	ParseTime(name string) (time.Time, error)
+	String() string
+}
+
+// StrptimeTimeParser requires the whole name to match a strptime pattern
+type StrptimeTimeParser struct {
+	Pattern  string
+	Location *time.Location // Location of timestamps without time zone, UTC if nil
+}
+
+func (p StrptimeTimeParser) ParseTime(name string) (time.Time, error) {
+	if p.Location != nil {
+		return timefmt.ParseInLocation(name, p.Pattern, p.Location)
+	}
+	return timefmt.Parse(name, p.Pattern)
+}
+
+func (p StrptimeTimeParser) String() string {
+	return fmt.Sprintf("pattern '%v'", p.Pattern)
+}
+
+// RegexTimeParser extracts the timestamp from an arbitrary part of a name
+// using named capture groups. Either a single "ts" group parsed using a
+// strptime pattern, or the "year", "month" and "day" groups and optionally
+// the "hour", "minute", "second" and "tz" groups are required. The optional
+// "series" group defines the series (see SeriesParser). Other named groups
+// are ignored
+type RegexTimeParser struct {
+	regex    *regexp.Regexp
+	pattern  string
+	groups   map[string]int
+	location *time.Location
+}
+
+// NewRegexTimeParser compiles expression and verifies it contains the
+// required capture groups. pattern is only used for the "ts" group
+func NewRegexTimeParser(expression string, pattern string) (*RegexTimeParser, error) {
+	return NewRegexTimeParserInLocation(expression, pattern, time.UTC)
+}
+
+// NewRegexTimeParserInLocation is like NewRegexTimeParser, but timestamps
+// without time zone are in location instead of UTC
+func NewRegexTimeParserInLocation(expression string, pattern string, location *time.Location) (*RegexTimeParser, error) {
+	regex, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex '%v': %w", expression, err)
+	}
+
+	groups := make(map[string]int)
+	for i, name := range regex.SubexpNames() {
+		if name != "" {
+			groups[name] = i
+		}
+	}
+
+	if _, ok := groups[RegexGroupTimestamp]; ok {
+		if pattern == "" {
+			return nil, fmt.Errorf("invalid regex '%v': group '%v' requires a pattern", expression, RegexGroupTimestamp)
+		}
+	} else {
+		missing := []string{}
+		for _, name := range []string{RegexGroupYear, RegexGroupMonth, RegexGroupDay} {
+			if _, ok := groups[name]; !ok {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("invalid regex '%v': missing named group(s) %v (or a single '%v' group)", expression, strings.Join(missing, ", "), RegexGroupTimestamp)
+		}
+	}
+
+	return &RegexTimeParser{regex: regex, pattern: pattern, groups: groups, location: location}, nil
+}
+
+// Regex returns the compiled regular expression
+func (p *RegexTimeParser) Regex() *regexp.Regexp {
+	return p.regex
+}
+
+func (p *RegexTimeParser) ParseTime(name string) (time.Time, error) {
+	match := p.regex.FindStringSubmatch(name)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("%v does not match regex '%v'", name, p.regex)
+	}
+
+	if i, ok := p.groups[RegexGroupTimestamp]; ok {
+		return timefmt.ParseInLocation(match[i], p.pattern, p.location)
+	}
+
+	values := make(map[string]int)
+	for _, group := range []string{RegexGroupYear, RegexGroupMonth, RegexGroupDay, RegexGroupHour, RegexGroupMinute, RegexGroupSecond} {
+		i, ok := p.groups[group]
+		if !ok {
+			continue
+		}
+		if match[i] == "" {
+			if group == RegexGroupYear || group == RegexGroupMonth || group == RegexGroupDay {
+				return time.Time{}, fmt.Errorf("missing %v in %v", group, name)
+			}
+			continue
+		}
+		value, err := strconv.Atoi(match[i])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %v '%v' in %v", group, match[i], name)
+		}
+		values[group] = value
+	}
+
+	year, month, day := values[RegexGroupYear], time.Month(values[RegexGroupMonth]), values[RegexGroupDay]
+	hour, minute, second := values[RegexGroupHour], values[RegexGroupMinute], values[RegexGroupSecond]
+	if month < time.January || month > time.December {
+		return time.Time{}, fmt.Errorf("%v '%v' out of range 1-12 in %v", RegexGroupMonth, values[RegexGroupMonth], name)
+	}
+	// Day 0 of the next month is the last day of month
+	if days := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day(); day < 1 || day > days {
+		return time.Time{}, fmt.Errorf("%v '%v' out of range 1-%v in %v", RegexGroupDay, day, days, name)
+	}
+	if hour < 0 || hour > 23 {
+		return time.Time{}, fmt.Errorf("%v '%v' out of range 0-23 in %v", RegexGroupHour, hour, name)
+	}
+	if minute < 0 || minute > 59 {
+		return time.Time{}, fmt.Errorf("%v '%v' out of range 0-59 in %v", RegexGroupMinute, minute, name)
+	}
+	if second < 0 || second > 59 {
+		return time.Time{}, fmt.Errorf("%v '%v' out of range 0-59 in %v", RegexGroupSecond, second, name)
+	}
+
+	location := p.location
+	if i, ok := p.groups[RegexGroupTimeZone]; ok && match[i] != "" {
+		var err error
+		location, err = parseTimeZone(match[i])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %v '%v' in %v: %w", RegexGroupTimeZone, match[i], name, err)
+		}
+	}
+
+	// time.Date normalizes values that don't exist, e.g. local times
+	// skipped by a daylight saving time change
+	t := time.Date(year, month, day, hour, minute, second, 0, location)
+	if y, m, d := t.Date(); y != year || m != month || d != day || t.Hour() != hour || t.Minute() != minute || t.Second() != second {
+		return time.Time{}, fmt.Errorf("time %04d-%02d-%02d %02d:%02d:%02d does not exist in %v in %v", year, month, day, hour, minute, second, location, name)
+	}
+	return t, nil
+}
+
+// HasSeries reports whether the regex contains a "series" group
+func (p *RegexTimeParser) HasSeries() bool {
+	_, ok := p.groups[RegexGroupSeries]
+	return ok
+}
+
+func (p *RegexTimeParser) ParseSeries(name string) (string, bool) {
+	match := p.regex.FindStringSubmatch(name)
+	if match == nil {
+		return "", false
+	}
+	if i, ok := p.groups[RegexGroupSeries]; ok {
+		return match[i], true
+	}
+	return "", true
+}
+
+func (p *RegexTimeParser) String() string {
+	return fmt.Sprintf("regex '%v'", p.regex)
+}
+
+// parseTimeZone parses time zone offsets like Z, +02, +0200 or +02:00
+func parseTimeZone(s string) (*time.Location, error) {
+	if s == "Z" || s == "z" {
+		return time.UTC, nil
+	}
+
+	for _, layout := range []string{"-07", "-0700", "-07:00"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			_, offset := t.Zone()
+			return time.FixedZone("", offset), nil
+		}
+	}
+
+	return nil, fmt.Errorf("expected Z or an offset like +02, +0200 or +02:00")
+}
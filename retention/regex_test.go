@@ -0,0 +1,210 @@
+package retention
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegexTimeParserGroups(t *testing.T) {
+	testCases := []struct {
+		regex        string
+		name         string
+		expectedTime time.Time
+	}{
+		{
+			regex:        `-(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})\.`,
+			name:         "backup-host1-2000-01-02.tar.gz",
+			expectedTime: time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			regex:        `(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})T(?P<hour>\d{2})-(?P<minute>\d{2})-(?P<second>\d{2})(?P<tz>Z|[+-]\d{4})`,
+			name:         "backup-host1-2000-01-02T03-04-05Z.tar.gz",
+			expectedTime: time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			regex:        `(?P<year>\d{4})(?P<month>\d{2})(?P<day>\d{2})_(?P<hour>\d{2})(?P<minute>\d{2})(?P<tz>[+-]\d{2}:\d{2})?`,
+			name:         "host1_20000102_0304+02:00.sql",
+			expectedTime: time.Date(2000, 1, 2, 3, 4, 0, 0, time.FixedZone("", 2*60*60)),
+		},
+		{
+			regex:        `^(?P<host>[a-z0-9]+)-(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})$`,
+			name:         "host1-2000-01-02",
+			expectedTime: time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parser, err := NewRegexTimeParser(testCase.regex, "")
+			if err != nil {
+				t.Fatalf("Failed to create parser: %v", err)
+			}
+
+			actual, err := parser.ParseTime(testCase.name)
+			if err != nil {
+				t.Fatalf("Failed to parse %v: %v", testCase.name, err)
+			}
+			if !actual.Equal(testCase.expectedTime) {
+				t.Errorf("Expected %v, got %v", testCase.expectedTime, actual)
+			}
+		})
+	}
+}
+
+func TestRegexTimeParserTimestampGroup(t *testing.T) {
+	// Arrange
+	parser, err := NewRegexTimeParser(`^backup-[a-z0-9]+-(?P<ts>.+)\.tar\.gz$`, PatternAlmostISO8601DateAndTime)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	// Act
+	actual, err := parser.ParseTime("backup-host1-2000-01-01T00-00-00Z.tar.gz")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if expected := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC); !actual.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestRegexTimeParserNoMatch(t *testing.T) {
+	parser, err := NewRegexTimeParser(`^backup-(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})$`, "")
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	if _, err := parser.ParseTime("foo-2000-01-01"); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestNewRegexTimeParserInvalid(t *testing.T) {
+	testCases := []struct {
+		regex   string
+		pattern string
+	}{
+		{`(?P<year>\d{4}`, ""},
+		{`(?P<year>\d{4})-(?P<month>\d{2})`, ""},
+		{`(\d{4})-(\d{2})-(\d{2})`, ""},
+		{`(?P<ts>.+)`, ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.regex, func(t *testing.T) {
+			if _, err := NewRegexTimeParser(testCase.regex, testCase.pattern); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+}
+
+func TestTimeParserDefaultLocation(t *testing.T) {
+	// Arrange
+	location, err := time.LoadLocation("Europe/Zurich")
+	if err != nil {
+		t.Fatalf("Failed to load location: %v", err)
+	}
+	regexParser, err := NewRegexTimeParserInLocation(`^(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})T(?P<hour>\d{2})(?P<tz>Z)?$`, "", location)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	testCases := []struct {
+		parser       TimeParser
+		name         string
+		expectedTime time.Time
+	}{
+		{StrptimeTimeParser{Pattern: "%Y-%m-%dT%H", Location: location}, "2000-07-01T12", time.Date(2000, 7, 1, 10, 0, 0, 0, time.UTC)},
+		{StrptimeTimeParser{Pattern: "%Y-%m-%dT%H%z", Location: location}, "2000-07-01T12Z", time.Date(2000, 7, 1, 12, 0, 0, 0, time.UTC)},
+		{regexParser, "2000-01-01T12", time.Date(2000, 1, 1, 11, 0, 0, 0, time.UTC)},
+		{regexParser, "2000-01-01T12Z", time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, testCase := range testCases {
+		// Act
+		actual, err := testCase.parser.ParseTime(testCase.name)
+
+		// Assert
+		if err != nil {
+			t.Errorf("%v: Failed to parse: %v", testCase.name, err)
+			continue
+		}
+		if !actual.Equal(testCase.expectedTime) {
+			t.Errorf("%v: Expected %v, got %v", testCase.name, testCase.expectedTime, actual)
+		}
+	}
+}
+
+func TestRegexTimeParserInvalidValues(t *testing.T) {
+	testCases := []struct {
+		regex string
+		name  string
+	}{
+		{`-(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})`, "backup-2000-13-45"},
+		{`-(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})`, "backup-2000-00-01"},
+		{`-(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})`, "backup-2000-01-00"},
+		{`-(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})`, "backup-2001-02-29"},
+		{`-(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})`, "backup-2000-04-31"},
+		{`-(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})T(?P<hour>\d{2})`, "backup-2000-01-01T24"},
+		{`-(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})T(?P<hour>\d{2})(?P<minute>\d{2})`, "backup-2000-01-01T0060"},
+		{`-(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})T(?P<hour>\d{2})(?P<minute>\d{2})(?P<second>\d{2})`, "backup-2000-01-01T000060"},
+		{`-(?P<year>\d{4})-(?P<month>\d{2})?-(?P<day>\d{2})`, "backup-2000--01"},
+		{`-(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d*)$`, "backup-2000-01-"},
+		{`-(?P<year>\d*)-(?P<month>\d{2})-(?P<day>\d{2})`, "backup--01-01"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parser, err := NewRegexTimeParser(testCase.regex, "")
+			if err != nil {
+				t.Fatalf("Failed to create parser: %v", err)
+			}
+
+			if actual, err := parser.ParseTime(testCase.name); err == nil {
+				t.Errorf("Expected error, got %v", actual)
+			}
+		})
+	}
+}
+
+func TestRegexTimeParserNonExistentLocalTime(t *testing.T) {
+	// Arrange
+	location, err := time.LoadLocation("Europe/Zurich")
+	if err != nil {
+		t.Fatalf("Failed to load location: %v", err)
+	}
+	parser, err := NewRegexTimeParserInLocation(`^(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})T(?P<hour>\d{2})(?P<minute>\d{2})$`, "", location)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	// Act
+	actual, err := parser.ParseTime("2000-03-26T0230")
+
+	// Assert
+	if err == nil {
+		t.Errorf("Expected error, got %v", actual)
+	}
+}
+
+func TestRegexTimeParserOptionalGroupsEmpty(t *testing.T) {
+	// Arrange
+	parser, err := NewRegexTimeParser(`^(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})(T(?P<hour>\d{2}))?$`, "")
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	// Act
+	actual, err := parser.ParseTime("2000-02-29")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if expected := time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC); !actual.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
@@ -0,0 +1,462 @@
+package retention
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Rule marks the candidates it keeps by setting Keep and Reason. Candidates
+// already kept by a rule applied before must not be changed
+type Rule interface {
+	Apply(objects []PruneCandidate)
+}
+
+// KeepLastRule keeps the KeepCount newest candidates, regardless of how many
+// of them share the same hour, day, etc.
+type KeepLastRule struct {
+	KeepCount int
+}
+
+func (r *KeepLastRule) Apply(objects []PruneCandidate) {
+	candidates := candidatePointers(objects)
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		// WARNING: not a less function, but a more function, so we can start from the beginning of the slice
+		return candidates[i].Object.Time.After(candidates[j].Object.Time)
+	})
+
+	currentKeepCount := 0
+	for _, candidate := range candidates {
+		if currentKeepCount == r.KeepCount {
+			break
+		}
+		if !candidate.Keep {
+			candidate.Keep = true
+			currentKeepCount++
+			candidate.Reason = &KeepReason{Rule: "last", Number: currentKeepCount, Bucket: KeepLastBucketName(candidate.Object.Time)}
+		}
+	}
+}
+
+func KeepLastBucketName(exactTime time.Time) string {
+	return exactTime.Format("2006-01-02T15:04:05")
+}
+
+// KeepWithinRule keeps all candidates newer than Within before Now
+type KeepWithinRule struct {
+	Within Duration
+	Now    time.Time
+}
+
+func (r *KeepWithinRule) Apply(objects []PruneCandidate) {
+	candidates := filterWithin(objects, r.Within, r.Now)
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		// WARNING: not a less function, but a more function, so the newest candidate gets #1
+		return candidates[i].Object.Time.After(candidates[j].Object.Time)
+	})
+
+	currentKeepCount := 0
+	for _, candidate := range candidates {
+		if !candidate.Keep {
+			candidate.Keep = true
+			currentKeepCount++
+			candidate.Reason = &KeepReason{Rule: "within", Number: currentKeepCount, Bucket: KeepLastBucketName(candidate.Object.Time)}
+		}
+	}
+}
+
+// KeepWithinBucketRule keeps the newest candidate of each bucket (e.g. each
+// day) newer than Within before Now, like restic's --keep-within-daily etc.
+type KeepWithinBucketRule struct {
+	Within      Duration
+	Now         time.Time
+	Pick        Pick
+	Name        string // Name of the bucket rule, e.g. daily
+	TimeConvert func(time time.Time) time.Time
+	BucketName  func(time time.Time) string
+}
+
+func NewKeepWithinHourlyRule(within Duration, now time.Time) KeepWithinBucketRule {
+	return KeepWithinBucketRule{Within: within, Now: now, Name: "hourly", TimeConvert: KeepHourlyTimeConvert, BucketName: KeepHourlyBucketName}
+}
+
+func NewKeepWithinDailyRule(within Duration, now time.Time) KeepWithinBucketRule {
+	return KeepWithinBucketRule{Within: within, Now: now, Name: "daily", TimeConvert: KeepDailyTimeConvert, BucketName: KeepDailyBucketName}
+}
+
+func NewKeepWithinWeeklyRule(within Duration, now time.Time, weekStart WeekStart) KeepWithinBucketRule {
+	return KeepWithinBucketRule{Within: within, Now: now, Name: "weekly", TimeConvert: WeeklyTimeConvert(weekStart), BucketName: WeeklyBucketName(weekStart)}
+}
+
+func NewKeepWithinMonthlyRule(within Duration, now time.Time) KeepWithinBucketRule {
+	return KeepWithinBucketRule{Within: within, Now: now, Name: "monthly", TimeConvert: KeepMonthlyTimeConvert, BucketName: KeepMonthlyBucketName}
+}
+
+func NewKeepWithinYearlyRule(within Duration, now time.Time) KeepWithinBucketRule {
+	return KeepWithinBucketRule{Within: within, Now: now, Name: "yearly", TimeConvert: KeepYearlyTimeConvert, BucketName: KeepYearlyBucketName}
+}
+
+func (r *KeepWithinBucketRule) Apply(objects []PruneCandidate) {
+	groups := groupCandidatesBy(filterWithin(objects, r.Within, r.Now), r.TimeConvert)
+
+	currentKeepCount := 0
+	for _, key := range sortedKeys(groups) {
+		objectToKeep := r.Pick.candidate(groups[key])
+
+		// Set keep if not yet set
+		if !objectToKeep.Keep {
+			objectToKeep.Keep = true
+			currentKeepCount++
+			objectToKeep.Reason = &KeepReason{Rule: "within-" + r.Name, Number: currentKeepCount, Bucket: r.BucketName(objectToKeep.Object.Time)}
+		}
+	}
+}
+
+// filterWithin returns the candidates newer than within before now
+func filterWithin(objects []PruneCandidate, within Duration, now time.Time) []*PruneCandidate {
+	candidates := []*PruneCandidate{}
+	cutoff := within.Before(now)
+	for i := 0; i < len(objects); i++ {
+		if objects[i].Object.Time.After(cutoff) {
+			candidates = append(candidates, &objects[i])
+		}
+	}
+	return candidates
+}
+
+type KeepHourlyRule struct {
+	KeepCount int
+	Pick      Pick
+	Strict    bool // Disables keeping the oldest candidate if KeepCount cannot be satisfied otherwise
+}
+
+func (r *KeepHourlyRule) Apply(objects []PruneCandidate) {
+	groups := groupBy(objects, KeepHourlyTimeConvert)
+	applyKeepRule(groups, r.KeepCount, r.Pick, r.Strict, "hourly", KeepHourlyBucketName)
+}
+
+// KeepHourlyTimeConvert converts to the start of the hour. The hour repeated
+// when daylight saving time ends results in two distinct keys
+func KeepHourlyTimeConvert(exactTime time.Time) time.Time {
+	sinceHour := time.Duration(exactTime.Minute())*time.Minute + time.Duration(exactTime.Second())*time.Second + time.Duration(exactTime.Nanosecond())
+	return exactTime.Add(-sinceHour).UTC()
+}
+
+func KeepHourlyBucketName(exactTime time.Time) string {
+	return exactTime.Format("2006-01-02T15")
+}
+
+type KeepDailyRule struct {
+	KeepCount int
+	Pick      Pick
+	Strict    bool // Disables keeping the oldest candidate if KeepCount cannot be satisfied otherwise
+}
+
+func (r *KeepDailyRule) Apply(objects []PruneCandidate) {
+	groups := groupBy(objects, KeepDailyTimeConvert)
+	applyKeepRule(groups, r.KeepCount, r.Pick, r.Strict, "daily", KeepDailyBucketName)
+}
+
+func KeepDailyTimeConvert(exactTime time.Time) time.Time {
+	year, month, day := exactTime.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func KeepDailyBucketName(exactTime time.Time) string {
+	return exactTime.Format("2006-01-02")
+}
+
+type KeepWeeklyRule struct {
+	KeepCount int
+	Pick      Pick
+	Strict    bool // Disables keeping the oldest candidate if KeepCount cannot be satisfied otherwise
+	WeekStart WeekStart
+}
+
+func (r *KeepWeeklyRule) Apply(objects []PruneCandidate) {
+	groups := groupBy(objects, WeeklyTimeConvert(r.WeekStart))
+	applyKeepRule(groups, r.KeepCount, r.Pick, r.Strict, "weekly", WeeklyBucketName(r.WeekStart))
+}
+
+// WeeklyTimeConvert returns a function converting to the first day of the
+// week starting on weekStart
+func WeeklyTimeConvert(weekStart WeekStart) func(exactTime time.Time) time.Time {
+	if weekStart == WeekStartMonday {
+		return KeepWeeklyTimeConvert
+	}
+	return func(exactTime time.Time) time.Time {
+		year, month, day := exactTime.Date()
+		daysSinceStart := (int(exactTime.Weekday()) - int(weekStart.Weekday()) + 7) % 7
+		return time.Date(year, month, day-daysSinceStart, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// WeeklyBucketName returns a function naming the week starting on weekStart.
+// Weeks starting on Monday are named by ISO 8601 week, other weeks by their
+// first day
+func WeeklyBucketName(weekStart WeekStart) func(exactTime time.Time) string {
+	if weekStart == WeekStartMonday {
+		return KeepWeeklyBucketName
+	}
+	timeConvert := WeeklyTimeConvert(weekStart)
+	return func(exactTime time.Time) string {
+		return "week of " + timeConvert(exactTime).Format("2006-01-02")
+	}
+}
+
+// KeepWeeklyTimeConvert converts to the Monday of the ISO 8601 week, so days
+// belonging to the same ISO week (see time.ISOWeek) share the same key, even
+// if the week spans two years
+func KeepWeeklyTimeConvert(exactTime time.Time) time.Time {
+	year, month, day := exactTime.Date()
+	daysSinceMonday := (int(exactTime.Weekday()) + 6) % 7
+	return time.Date(year, month, day-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+}
+
+func KeepWeeklyBucketName(exactTime time.Time) string {
+	year, week := exactTime.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+type KeepMonthlyRule struct {
+	KeepCount int
+	Pick      Pick
+	Strict    bool // Disables keeping the oldest candidate if KeepCount cannot be satisfied otherwise
+}
+
+func (r *KeepMonthlyRule) Apply(objects []PruneCandidate) {
+	groups := groupBy(objects, KeepMonthlyTimeConvert)
+	applyKeepRule(groups, r.KeepCount, r.Pick, r.Strict, "monthly", KeepMonthlyBucketName)
+}
+
+func KeepMonthlyTimeConvert(exactTime time.Time) time.Time {
+	return time.Date(exactTime.Year(), exactTime.Month(), 0, 0, 0, 0, 0, time.UTC)
+}
+
+func KeepMonthlyBucketName(exactTime time.Time) string {
+	return exactTime.Format("2006-01")
+}
+
+type KeepYearlyRule struct {
+	KeepCount int
+	Pick      Pick
+	Strict    bool // Disables keeping the oldest candidate if KeepCount cannot be satisfied otherwise
+}
+
+func (r *KeepYearlyRule) Apply(objects []PruneCandidate) {
+	groups := groupBy(objects, KeepYearlyTimeConvert)
+	applyKeepRule(groups, r.KeepCount, r.Pick, r.Strict, "yearly", KeepYearlyBucketName)
+}
+
+type KeepQuarterlyRule struct {
+	KeepCount int
+	Pick      Pick
+	Strict    bool // Disables keeping the oldest candidate if KeepCount cannot be satisfied otherwise
+}
+
+func (r *KeepQuarterlyRule) Apply(objects []PruneCandidate) {
+	groups := groupBy(objects, KeepQuarterlyTimeConvert)
+	applyKeepRule(groups, r.KeepCount, r.Pick, r.Strict, "quarterly", KeepQuarterlyBucketName)
+}
+
+func KeepQuarterlyTimeConvert(exactTime time.Time) time.Time {
+	month := exactTime.Month()
+	return time.Date(exactTime.Year(), month-(month-1)%3, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func KeepQuarterlyBucketName(exactTime time.Time) string {
+	return fmt.Sprintf("%d-Q%d", exactTime.Year(), (exactTime.Month()-1)/3+1)
+}
+
+type KeepHalfYearlyRule struct {
+	KeepCount int
+	Pick      Pick
+	Strict    bool // Disables keeping the oldest candidate if KeepCount cannot be satisfied otherwise
+}
+
+func (r *KeepHalfYearlyRule) Apply(objects []PruneCandidate) {
+	groups := groupBy(objects, KeepHalfYearlyTimeConvert)
+	applyKeepRule(groups, r.KeepCount, r.Pick, r.Strict, "half-yearly", KeepHalfYearlyBucketName)
+}
+
+func KeepHalfYearlyTimeConvert(exactTime time.Time) time.Time {
+	month := exactTime.Month()
+	return time.Date(exactTime.Year(), month-(month-1)%6, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func KeepHalfYearlyBucketName(exactTime time.Time) string {
+	return fmt.Sprintf("%d-H%d", exactTime.Year(), (exactTime.Month()-1)/6+1)
+}
+
+func KeepYearlyTimeConvert(exactTime time.Time) time.Time {
+	return time.Date(exactTime.Year(), 0, 0, 0, 0, 0, 0, time.UTC)
+}
+
+func KeepYearlyBucketName(exactTime time.Time) string {
+	return exactTime.Format("2006")
+}
+
+// KeepReason records which rule kept a candidate
+type KeepReason struct {
+	Rule   string `json:"rule"`
+	Number int    `json:"number"`
+	Bucket string `json:"bucket"`
+	// Oldest is set if the candidate was kept because the keep count of the
+	// rule could not be satisfied otherwise
+	Oldest bool `json:"oldest"`
+}
+
+// KeepReasonPinned is the KeepReason.Rule of pinned candidates
+const KeepReasonPinned = "pinned"
+
+// KeepReasonTooYoung is the KeepReason.Rule of candidates younger than
+// Configuration.MinAge
+const KeepReasonTooYoung = "too young"
+
+func (r KeepReason) String() string {
+	switch r.Rule {
+	case KeepReasonPinned, KeepReasonInvalid:
+		return fmt.Sprintf("%s, %s", r.Rule, r.Bucket)
+	case KeepReasonTooYoung:
+		return r.Rule
+	}
+	rule := r.Rule
+	if r.Oldest {
+		rule += "[oldest]"
+	}
+	return fmt.Sprintf("%s #%d, %s", rule, r.Number, r.Bucket)
+}
+
+func candidatePointers(objects []PruneCandidate) []*PruneCandidate {
+	candidates := make([]*PruneCandidate, 0, len(objects))
+	for i := 0; i < len(objects); i++ {
+		candidates = append(candidates, &objects[i])
+	}
+	return candidates
+}
+
+func groupBy(objects []PruneCandidate, timeConvert func(time time.Time) time.Time) map[time.Time][]*PruneCandidate {
+	return groupCandidatesBy(candidatePointers(objects), timeConvert)
+}
+
+func groupCandidatesBy(candidates []*PruneCandidate, timeConvert func(time time.Time) time.Time) map[time.Time][]*PruneCandidate {
+	groups := make(map[time.Time][]*PruneCandidate)
+
+	for _, object := range candidates {
+		relevantTime := timeConvert(object.Object.Time)
+
+		if value, ok := groups[relevantTime]; ok {
+			groups[relevantTime] = append(value, object)
+		} else {
+			groups[relevantTime] = []*PruneCandidate{object}
+		}
+	}
+
+	return groups
+}
+
+// sortedKeys returns the keys of groups, newest first
+func sortedKeys(groups map[time.Time][]*PruneCandidate) []time.Time {
+	keys := make([]time.Time, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		// WARNING: not a less function, but a more function, so we can start from the beginning of the slice
+		return keys[i].After(keys[j])
+	})
+
+	return keys
+}
+
+// applyKeepRule keeps the picked candidate of the keepCount newest buckets.
+// Unless strict, the oldest candidate is kept if there are not enough buckets
+// to satisfy keepCount
+func applyKeepRule(groups map[time.Time][]*PruneCandidate, keepCount int, pick Pick, strict bool, ruleName string, bucketName func(time time.Time) string) int {
+	// get a sorted slice of the keys of the array
+	keys := sortedKeys(groups)
+	if len(keys) == 0 {
+		return 0
+	}
+
+	currentKeepCount := 0
+	for _, key := range keys {
+		if currentKeepCount == keepCount {
+			break
+		}
+
+		relevantTimeObjects := groups[key]
+
+		objectToKeep := pick.candidate(relevantTimeObjects)
+
+		if objectToKeep == nil {
+			continue
+		}
+
+		// Set keep if not yet set
+		if !objectToKeep.Keep {
+			objectToKeep.Keep = true
+			currentKeepCount++
+			objectToKeep.Reason = &KeepReason{Rule: ruleName, Number: currentKeepCount, Bucket: bucketName(objectToKeep.Object.Time)}
+		}
+	}
+
+	// Keep oldest object if keep count not satisfied
+	if currentKeepCount < keepCount && !strict {
+		latestKey := keys[len(keys)-1]
+		relevantTimeObjects := groups[latestKey]
+
+		objectToKeep := getOldest(relevantTimeObjects)
+
+		if objectToKeep != nil {
+			// Set keep if not yet set
+			if !objectToKeep.Keep {
+				objectToKeep.Keep = true
+				currentKeepCount++
+				objectToKeep.Reason = &KeepReason{Rule: ruleName, Number: currentKeepCount, Bucket: bucketName(objectToKeep.Object.Time), Oldest: true}
+			}
+		}
+	}
+
+	return currentKeepCount
+}
+
+func getNewest(candidates []*PruneCandidate) *PruneCandidate {
+	return firstOrNilSorted(candidates, sortAndTakeNewest)
+}
+
+func getOldest(candidates []*PruneCandidate) *PruneCandidate {
+	return firstOrNilSorted(candidates, sortAndTakeOldest)
+}
+
+func firstOrNilSorted(candidates []*PruneCandidate, sortAndFirstFunc func(candidates []*PruneCandidate) *PruneCandidate) *PruneCandidate {
+	l := len(candidates)
+	if l == 0 {
+		return nil
+	} else if l == 1 {
+		// Take single
+		return candidates[0]
+	} else {
+		return sortAndFirstFunc(candidates)
+	}
+}
+
+func sortAndTakeNewest(candidates []*PruneCandidate) *PruneCandidate {
+	// Take newest
+	sort.SliceStable(candidates, func(i, j int) bool {
+		// WARNING: not a less function, but a more function, so we can take the first element
+		return candidates[i].Object.Time.After(candidates[j].Object.Time)
+	})
+	return candidates[0]
+}
+
+func sortAndTakeOldest(candidates []*PruneCandidate) *PruneCandidate {
+	// Take oldest
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Object.Time.Before(candidates[j].Object.Time)
+	})
+	return candidates[0]
+}
@@ -0,0 +1,51 @@
+package retention
+
+import (
+	"sort"
+	"strings"
+)
+
+// RegexGroupSeries is the name of the capture group of a RegexTimeParser
+// defining the series of a file/directory
+const RegexGroupSeries = "series"
+
+// SeriesParser determines the series a file/directory belongs to. Retention
+// rules are applied to each series independently. Files/directories for which
+// ok is false do not belong to any series and are skipped
+type SeriesParser interface {
+	ParseSeries(name string) (series string, ok bool)
+}
+
+// PrefixSeriesParser assigns files/directories to the series of the longest
+// matching prefix
+type PrefixSeriesParser struct {
+	Prefixes []string
+}
+
+func (p PrefixSeriesParser) ParseSeries(name string) (string, bool) {
+	series, ok := "", false
+	for _, prefix := range p.Prefixes {
+		if strings.HasPrefix(name, prefix) && len(prefix) >= len(series) {
+			series, ok = prefix, true
+		}
+	}
+	return series, ok
+}
+
+// splitBySeries sorts objects by series and returns a sub-slice per series.
+// The sub-slices share the backing array of objects
+func splitBySeries(objects []PruneCandidate) [][]PruneCandidate {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].Object.Series < objects[j].Object.Series
+	})
+
+	series := [][]PruneCandidate{}
+	start := 0
+	for i := 1; i <= len(objects); i++ {
+		if i == len(objects) || objects[i].Object.Series != objects[start].Object.Series {
+			series = append(series, objects[start:i])
+			start = i
+		}
+	}
+	return series
+}
@@ -0,0 +1,56 @@
+package retention
+
+import (
+	"testing"
+)
+
+func TestPrefixSeriesParser(t *testing.T) {
+	parser := PrefixSeriesParser{Prefixes: []string{"host1-", "host1-db-", "host2-"}}
+
+	testCases := []struct {
+		name           string
+		expectedSeries string
+		expectedOk     bool
+	}{
+		{"host1-2000-01-01", "host1-", true},
+		{"host1-db-2000-01-01", "host1-db-", true},
+		{"host2-2000-01-01", "host2-", true},
+		{"host3-2000-01-01", "", false},
+	}
+
+	for _, testCase := range testCases {
+		series, ok := parser.ParseSeries(testCase.name)
+		if series != testCase.expectedSeries || ok != testCase.expectedOk {
+			t.Errorf("%v: Got (%v, %v), expected (%v, %v)", testCase.name, series, ok, testCase.expectedSeries, testCase.expectedOk)
+		}
+	}
+}
+
+func TestSplitBySeries(t *testing.T) {
+	// Arrange
+	objects := []PruneCandidate{
+		{Object: TimeStampedObject{Name: "a", Series: "b"}},
+		{Object: TimeStampedObject{Name: "b", Series: "a"}},
+		{Object: TimeStampedObject{Name: "c", Series: "b"}},
+		{Object: TimeStampedObject{Name: "d", Series: ""}},
+	}
+
+	// Act
+	series := splitBySeries(objects)
+
+	// Assert
+	if expected, actual := 3, len(series); actual != expected {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+	for i, expectedCount := range []int{1, 1, 2} {
+		if actual := len(series[i]); actual != expectedCount {
+			t.Errorf("Series #%d: Expected %v, got %v", i, expectedCount, actual)
+		}
+	}
+
+	// Sub-slices share the backing array
+	series[2][1].Keep = true
+	if !objects[3].Keep {
+		t.Errorf("Expected modification to be visible in objects")
+	}
+}
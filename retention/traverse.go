@@ -0,0 +1,220 @@
+package retention
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const PatternISO8601DateOnly = "%Y-%m-%d"
+const PatternAlmostISO8601DateAndTime = "%Y-%m-%dT%H-%M-%S%z"
+
+// EntryType selects which kind of directory entries are considered
+type EntryType int
+
+const (
+	EntryTypeDirectory EntryType = iota
+	EntryTypeFile
+	EntryTypeAny
+)
+
+var entryTypeNames = map[EntryType]string{
+	EntryTypeDirectory: "dir",
+	EntryTypeFile:      "file",
+	EntryTypeAny:       "any",
+}
+
+// ParseEntryType parses the name of an entry type: dir, file or any
+func ParseEntryType(s string) (EntryType, error) {
+	for entryType, name := range entryTypeNames {
+		if name == s {
+			return entryType, nil
+		}
+	}
+	return EntryTypeDirectory, fmt.Errorf("invalid entry type '%v': expected dir, file or any", s)
+}
+
+func (e EntryType) String() string {
+	return entryTypeNames[e]
+}
+
+func (e EntryType) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+func (e *EntryType) UnmarshalText(text []byte) error {
+	entryType, err := ParseEntryType(string(text))
+	if err != nil {
+		return err
+	}
+	*e = entryType
+	return nil
+}
+
+func (e EntryType) matches(entry fs.DirEntry) bool {
+	switch e {
+	case EntryTypeFile:
+		return entry.Type().IsRegular()
+	case EntryTypeAny:
+		return entry.IsDir() || entry.Type().IsRegular()
+	default:
+		return entry.IsDir()
+	}
+}
+
+// FileSystemTraverser retrieves timestamped files/directories from the file
+// system
+type FileSystemTraverser struct {
+	Pattern        string
+	Regex          string // Optional, see RegexTimeParser
+	SeriesPrefixes []string
+	Type           EntryType
+	Location       *time.Location // Location of timestamps without time zone, UTC if nil
+	PinMarker      string         // Name of the marker file pinning a directory, see DefaultPinMarker
+	Pins           []string       // Names or paths of pinned files/directories, see LoadPinList
+	ValidityChecks []ValidityCheck
+}
+
+// GetObjects returns all entries of the Type of the traverser inside basePath
+// with a name matching the Pattern of the traverser
+func (t *FileSystemTraverser) GetObjects(basePath string) ([]TimeStampedObject, error) {
+	// TODO: think about using File.Readdirnames as it should be much faster
+	// TODO: think about using a channel to send file names to for further processing
+
+	// CHECK https://bitfieldconsulting.com/golang/filesystems for more inspiration
+
+	parser, err := t.timeParser()
+	if err != nil {
+		return nil, err
+	}
+
+	seriesParser, err := t.seriesParser(parser)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := ParseWith(basePath, parser, seriesParser, t.Type, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	pin(objects, t.PinMarker, t.Pins)
+	validate(objects, t.ValidityChecks)
+
+	return objects, nil
+}
+
+func (t *FileSystemTraverser) timeParser() (TimeParser, error) {
+	location := t.Location
+	if location == nil {
+		location = time.UTC
+	}
+	if t.Regex != "" {
+		return NewRegexTimeParserInLocation(t.Regex, t.Pattern, location)
+	}
+	return StrptimeTimeParser{Pattern: t.Pattern, Location: location}, nil
+}
+
+// seriesParser returns the parser for the series defined by either the series
+// prefixes or the "series" group of the regex, nil if no series are defined
+func (t *FileSystemTraverser) seriesParser(parser TimeParser) (SeriesParser, error) {
+	regexParser, isRegex := parser.(*RegexTimeParser)
+	hasRegexSeries := isRegex && regexParser.HasSeries()
+
+	switch {
+	case len(t.SeriesPrefixes) > 0 && hasRegexSeries:
+		return nil, fmt.Errorf("series prefixes cannot be combined with regex group '%v'", RegexGroupSeries)
+	case len(t.SeriesPrefixes) > 0:
+		return PrefixSeriesParser{Prefixes: t.SeriesPrefixes}, nil
+	case hasRegexSeries:
+		return regexParser, nil
+	default:
+		return nil, nil
+	}
+}
+
+// Parse returns a TimeStampedObject for each directory entry of entryType
+// with a name matching pattern. Entries not matching are skipped
+func Parse(basePath string, pattern string, entryType EntryType, entries []fs.DirEntry) ([]TimeStampedObject, error) {
+	return ParseWith(basePath, StrptimeTimeParser{Pattern: pattern}, nil, entryType, entries)
+}
+
+// ParseWith returns a TimeStampedObject for each directory entry of entryType
+// parser is able to parse the timestamp of. Entries not matching are skipped.
+// If seriesParser is not nil, it assigns the series of each entry and entries
+// not belonging to any series are skipped
+func ParseWith(basePath string, parser TimeParser, seriesParser SeriesParser, entryType EntryType, entries []fs.DirEntry) ([]TimeStampedObject, error) {
+
+	objects := []TimeStampedObject{}
+	candidateCount := 0
+
+	for _, entry := range entries {
+		if !entryType.matches(entry) {
+			continue
+		}
+		candidateCount++
+
+		// Parse
+		name := entry.Name() // Read once and cache to reduce system calls
+
+		var series string
+		timeName := name
+		if seriesParser != nil {
+			var ok bool
+			if series, ok = seriesParser.ParseSeries(name); !ok {
+				log.Printf("getObjects: skipping directory entry %v not belonging to any series", name)
+				continue
+			}
+			timeName = trimSeriesPrefix(name, series, parser, seriesParser)
+		}
+
+		t, err := parser.ParseTime(timeName)
+		if err != nil {
+			log.Printf("getObjects: failed to parse date for directory entry %v: %v", name, err)
+			continue
+		}
+
+		objects = append(objects, TimeStampedObject{Name: name, Path: path.Join(basePath, name), BasePath: basePath, Series: series, IsDir: entry.IsDir(), Time: t})
+	}
+
+	// Issue warning when no entry was matched by the pattern
+	// TODO: should we return an error?
+	if candidateCount > 0 && len(objects) == 0 {
+		log.Printf("traverse: failed to parse date for all directory entries. Is your %v valid?", parser)
+	}
+
+	return objects, nil
+}
+
+// trimSeriesPrefix removes the series prefix from name, so a strptime pattern
+// only has to match the remaining part of the name
+func trimSeriesPrefix(name string, series string, parser TimeParser, seriesParser SeriesParser) string {
+	_, isPrefix := seriesParser.(PrefixSeriesParser)
+	_, isStrptime := parser.(StrptimeTimeParser)
+	if isPrefix && isStrptime {
+		return strings.TrimPrefix(name, series)
+	}
+	return name
+}
+
+// TimeStampedObject is a file or directory with a timestamp parsed from its
+// name
+type TimeStampedObject struct {
+	Name     string
+	Path     string
+	BasePath string // Path of the Source the file/directory was found in
+	Series   string // Series the retention rules are applied to, see SeriesParser
+	Pin      string // Marker file or PinReasonList if pinned, see FileSystemTraverser
+	Invalid  string // Reason of the failed ValidityCheck, empty if valid
+	IsDir    bool
+	Time     time.Time
+}
@@ -0,0 +1,423 @@
+package retention
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestGetObjectsDateAndTimePattern(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	directories := []struct {
+		name         string
+		expectedTime time.Time
+	}{
+		{
+			name:         "2000-12-31T00-00-00Z",
+			expectedTime: time.Date(2000, 12, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "2001-01-01T00-00-00Z",
+			expectedTime: time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "2001-01-02T03-00-00Z",
+			expectedTime: time.Date(2001, 1, 2, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "2001-01-02T03-04-00Z",
+			expectedTime: time.Date(2001, 1, 2, 3, 4, 0, 0, time.UTC),
+		},
+		{
+			name:         "2001-01-02T03-04-05Z",
+			expectedTime: time.Date(2001, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}
+
+	for _, v := range directories {
+		err := os.Mkdir(path.Join(rootDir, v.name), 0755)
+		if err != nil {
+			t.Fatalf("Failed to create directory %s", v.name)
+		}
+	}
+
+	// Act
+	traverser := FileSystemTraverser{Pattern: PatternAlmostISO8601DateAndTime}
+	objects, err := traverser.GetObjects(rootDir)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to get objects for path %s: %v", rootDir, err)
+	}
+
+	if expected, actual := 5, len(objects); expected != actual {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+
+	objectsMap := toObjectsMap(objects)
+	for _, v := range directories {
+		object, ok := objectsMap[path.Join(rootDir, v.name)]
+		if !ok {
+			t.Errorf("Expected %v to be present", v)
+		}
+		if v.expectedTime != object.Time {
+			t.Fatalf("Expected %v, got %v", v.expectedTime, object.Time)
+		}
+	}
+}
+
+func TestGetObjectsDateOnlyPattern(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	directories := []struct {
+		name         string
+		expectedTime time.Time
+	}{
+		{
+			name:         "2000-12-30",
+			expectedTime: time.Date(2000, 12, 30, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "2000-12-31",
+			expectedTime: time.Date(2000, 12, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "2001-01-01",
+			expectedTime: time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "2001-01-02",
+			expectedTime: time.Date(2001, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, v := range directories {
+		err := os.Mkdir(path.Join(rootDir, v.name), 0755)
+		if err != nil {
+			t.Fatalf("Failed to create directory %s", v.name)
+		}
+	}
+
+	// Act
+	traverser := FileSystemTraverser{Pattern: PatternISO8601DateOnly}
+	objects, err := traverser.GetObjects(rootDir)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to get objects for path %s: %v", rootDir, err)
+	}
+
+	if expected, actual := 4, len(objects); expected != actual {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+
+	objectsMap := toObjectsMap(objects)
+	for _, v := range directories {
+		object, ok := objectsMap[path.Join(rootDir, v.name)]
+		if !ok {
+			t.Errorf("Expected %v to be present", v)
+		}
+		if v.expectedTime != object.Time {
+			t.Fatalf("Expected %v, got %v", v.expectedTime, object.Time)
+		}
+	}
+}
+
+// Expected to fail, but date is automatically "corrected"
+// 2000-02-31 => 2000-03-02 00:00:00 +0000 UTC
+// 2000-13-31 => 2001-01-31 00:00:00 +0000 UTC
+// Not sure if I like this or not
+func TestGetObjectsInvalidDates(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	directories := []struct {
+		name         string
+		expectedTime time.Time
+	}{
+		{
+			name: "2000-02-31",
+		},
+		{
+			name: "2000-13-31",
+		},
+	}
+
+	for _, v := range directories {
+		err := os.Mkdir(path.Join(rootDir, v.name), 0755)
+		if err != nil {
+			t.Fatalf("Failed to create directory %s", v.name)
+		}
+	}
+
+	// Act
+	traverser := FileSystemTraverser{Pattern: PatternISO8601DateOnly}
+	objects, err := traverser.GetObjects(rootDir)
+
+	if err != nil {
+		t.Fatalf("Failed to get objects for path %s: %v", rootDir, err)
+	}
+
+	if expected, actual := 2, len(objects); expected != actual {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestGetObjectsInvalidPattern(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	directories := []struct {
+		name         string
+		expectedTime time.Time
+	}{
+		{
+			name: "02.01.2000",
+		},
+		{
+			name: "foo-2000-12-31-bar",
+		},
+	}
+
+	for _, v := range directories {
+		err := os.Mkdir(path.Join(rootDir, v.name), 0755)
+		if err != nil {
+			t.Fatalf("Failed to create directory %s", v.name)
+		}
+	}
+
+	// Act
+	traverser := FileSystemTraverser{Pattern: PatternISO8601DateOnly}
+	objects, err := traverser.GetObjects(rootDir)
+
+	if err != nil {
+		t.Fatalf("Failed to get objects for path %s: %v", rootDir, err)
+	}
+
+	if expected, actual := 0, len(objects); expected != actual {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func toObjectsMap(objects []TimeStampedObject) map[string]*TimeStampedObject {
+	objectsMap := make(map[string]*TimeStampedObject)
+
+	for i := 0; i < len(objects); i++ {
+		object := &objects[i]
+		objectsMap[object.Path] = object
+	}
+
+	return objectsMap
+}
+
+func TestGetObjectsEntryTypes(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	directories := []string{"2000-01-01", "2000-01-02"}
+	files := []string{"2000-01-03", "2000-01-04", "2000-01-05"}
+
+	for _, name := range directories {
+		if err := os.Mkdir(path.Join(rootDir, name), 0755); err != nil {
+			t.Fatalf("Failed to create directory %s", name)
+		}
+	}
+	for _, name := range files {
+		if err := os.WriteFile(path.Join(rootDir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create file %s", name)
+		}
+	}
+
+	testCases := []struct {
+		entryType     EntryType
+		expectedCount int
+		expectedIsDir bool
+	}{
+		{EntryTypeDirectory, 2, true},
+		{EntryTypeFile, 3, false},
+		{EntryTypeAny, 5, false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.entryType.String(), func(t *testing.T) {
+			// Act
+			traverser := FileSystemTraverser{Pattern: PatternISO8601DateOnly, Type: testCase.entryType}
+			objects, err := traverser.GetObjects(rootDir)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Failed to get objects for path %s: %v", rootDir, err)
+			}
+
+			if expected, actual := testCase.expectedCount, len(objects); expected != actual {
+				t.Fatalf("Expected %v, got %v", expected, actual)
+			}
+
+			objectsMap := toObjectsMap(objects)
+			for _, name := range directories {
+				if object, ok := objectsMap[path.Join(rootDir, name)]; ok && !object.IsDir {
+					t.Errorf("Expected %v to be a directory", name)
+				}
+			}
+			for _, name := range files {
+				if object, ok := objectsMap[path.Join(rootDir, name)]; ok && object.IsDir {
+					t.Errorf("Expected %v to be a file", name)
+				}
+			}
+		})
+	}
+}
+
+func TestParseEntryType(t *testing.T) {
+	testCases := map[string]EntryType{
+		"dir":  EntryTypeDirectory,
+		"file": EntryTypeFile,
+		"any":  EntryTypeAny,
+	}
+
+	for name, expected := range testCases {
+		actual, err := ParseEntryType(name)
+		if err != nil {
+			t.Fatalf("Failed to parse %v: %v", name, err)
+		}
+		if actual != expected {
+			t.Errorf("Expected %v, got %v", expected, actual)
+		}
+		if actual.String() != name {
+			t.Errorf("Expected %v, got %v", name, actual.String())
+		}
+	}
+
+	if _, err := ParseEntryType("directory"); err == nil {
+		t.Errorf("Expected error for invalid entry type")
+	}
+}
+
+func TestGetObjectsRegex(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	directories := []struct {
+		name         string
+		expectedTime time.Time
+	}{
+		{
+			name:         "backup-host1-2000-01-01T00-00-00Z.tar.gz",
+			expectedTime: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "backup-host2-2000-01-02T03-04-05Z.tar.gz",
+			expectedTime: time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}
+
+	for _, v := range directories {
+		err := os.Mkdir(path.Join(rootDir, v.name), 0755)
+		if err != nil {
+			t.Fatalf("Failed to create directory %s", v.name)
+		}
+	}
+	if err := os.Mkdir(path.Join(rootDir, "unrelated"), 0755); err != nil {
+		t.Fatalf("Failed to create directory unrelated")
+	}
+
+	// Act
+	traverser := FileSystemTraverser{Pattern: PatternAlmostISO8601DateAndTime, Regex: `^backup-[a-z0-9]+-(?P<ts>.+)\.tar\.gz$`}
+	objects, err := traverser.GetObjects(rootDir)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to get objects for path %s: %v", rootDir, err)
+	}
+
+	if expected, actual := 2, len(objects); expected != actual {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+
+	objectsMap := toObjectsMap(objects)
+	for _, v := range directories {
+		object, ok := objectsMap[path.Join(rootDir, v.name)]
+		if !ok {
+			t.Fatalf("Expected %v to be present", v)
+		}
+		if v.expectedTime != object.Time {
+			t.Fatalf("Expected %v, got %v", v.expectedTime, object.Time)
+		}
+	}
+}
+
+func TestGetObjectsInvalidRegex(t *testing.T) {
+	traverser := FileSystemTraverser{Regex: `^backup-(?P<year>\d{4})$`}
+	if _, err := traverser.GetObjects(t.TempDir()); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestGetObjectsSeriesPrefixes(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	for _, name := range []string{"host1-2000-01-01", "host2-2000-01-01", "host3-2000-01-01"} {
+		if err := os.Mkdir(path.Join(rootDir, name), 0755); err != nil {
+			t.Fatalf("Failed to create directory %s", name)
+		}
+	}
+
+	// Act
+	traverser := FileSystemTraverser{Regex: `(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})$`, SeriesPrefixes: []string{"host1-", "host2-"}}
+	objects, err := traverser.GetObjects(rootDir)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to get objects for path %s: %v", rootDir, err)
+	}
+
+	if expected, actual := 2, len(objects); expected != actual {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+
+	objectsMap := toObjectsMap(objects)
+	if expected, actual := "host2-", objectsMap[path.Join(rootDir, "host2-2000-01-01")].Series; expected != actual {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestGetObjectsSeriesPrefixesAndRegexGroup(t *testing.T) {
+	traverser := FileSystemTraverser{Regex: `(?P<series>\w+)-(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})$`, SeriesPrefixes: []string{"host1-"}}
+	if _, err := traverser.GetObjects(t.TempDir()); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestGetObjectsSeriesPrefixesAndPattern(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	for _, name := range []string{"host1-2000-01-01", "host2-2000-01-02"} {
+		if err := os.Mkdir(path.Join(rootDir, name), 0755); err != nil {
+			t.Fatalf("Failed to create directory %s", name)
+		}
+	}
+
+	// Act
+	traverser := FileSystemTraverser{Pattern: PatternISO8601DateOnly, SeriesPrefixes: []string{"host1-", "host2-"}}
+	objects, err := traverser.GetObjects(rootDir)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to get objects for path %s: %v", rootDir, err)
+	}
+
+	if expected, actual := 2, len(objects); expected != actual {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+
+	object := toObjectsMap(objects)[path.Join(rootDir, "host2-2000-01-02")]
+	if expected, actual := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC), object.Time; expected != actual {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
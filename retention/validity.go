@@ -0,0 +1,180 @@
+package retention
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// KeepReasonInvalid is the KeepReason.Rule of candidates failing a validity
+// check
+const KeepReasonInvalid = "invalid"
+
+// SHA256SumExtension is the extension of the checksum files verified by
+// SHA256SumCheck
+const SHA256SumExtension = ".sha256sum"
+
+// ValidityCheck checks whether a timestamped file/directory is a complete
+// backup, e.g. not an empty or half-written directory. Invalid
+// files/directories are not seen by the rules, so they cannot take the place
+// of a valid backup
+type ValidityCheck interface {
+	// Check returns an error describing why object is invalid, nil if valid
+	Check(object TimeStampedObject) error
+}
+
+// RequiredGlobCheck requires directories to contain at least one entry whose
+// name matches Glob, e.g. *.tar.gz. Files are not checked
+type RequiredGlobCheck struct {
+	Glob string
+}
+
+func (c RequiredGlobCheck) Check(object TimeStampedObject) error {
+	if !object.IsDir {
+		return nil
+	}
+	matches, err := matchEntries(object.Path, c.Glob)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no entry matching %v", c.Glob)
+	}
+	return nil
+}
+
+// CompletionMarkerCheck requires directories to contain the file Marker, e.g.
+// .complete, written after the backup has been completed. Files require a file
+// next to them named like the file followed by Marker, e.g.
+// db-2000-01-01.sql.gz.complete
+type CompletionMarkerCheck struct {
+	Marker string
+}
+
+func (c CompletionMarkerCheck) Check(object TimeStampedObject) error {
+	markerPath := object.Path + c.Marker
+	if object.IsDir {
+		markerPath = path.Join(object.Path, c.Marker)
+	}
+	if _, err := os.Stat(markerPath); err != nil {
+		return fmt.Errorf("missing completion marker %v", c.Marker)
+	}
+	return nil
+}
+
+// SHA256SumCheck verifies the checksums of the sha256sum files (see
+// SHA256SumExtension) inside directories or next to files, e.g.
+// db-2000-01-01.sql.gz.sha256sum. Files/directories without sha256sum file are
+// valid
+type SHA256SumCheck struct{}
+
+func (c SHA256SumCheck) Check(object TimeStampedObject) error {
+	sumFiles := []string{object.Path + SHA256SumExtension}
+	if object.IsDir {
+		var err error
+		if sumFiles, err = matchEntries(object.Path, "*"+SHA256SumExtension); err != nil {
+			return err
+		}
+	}
+
+	for _, sumFile := range sumFiles {
+		if _, err := os.Stat(sumFile); os.IsNotExist(err) {
+			continue
+		}
+		if err := verifySHA256Sum(sumFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchEntries returns the paths of the entries of directory whose name
+// matches glob. Unlike filepath.Glob, the name of directory may contain glob
+// characters like [ or *
+func matchEntries(directory string, glob string) ([]string, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []string{}
+	for _, entry := range entries {
+		matched, err := filepath.Match(glob, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob '%v': %w", glob, err)
+		}
+		if matched {
+			matches = append(matches, path.Join(directory, entry.Name()))
+		}
+	}
+	return matches, nil
+}
+
+// verifySHA256Sum verifies the files listed in sumFile using the format of
+// sha256sum: a checksum followed by the path of the file relative to sumFile
+// per line
+func verifySHA256Sum(sumFile string) error {
+	file, err := os.Open(sumFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 || len(fields[0]) != sha256.Size*2 {
+			return fmt.Errorf("invalid checksum file %v", path.Base(sumFile))
+		}
+		expected := strings.ToLower(fields[0])
+		// Binary mode is indicated by a * in front of the name
+		name := strings.TrimPrefix(strings.TrimSpace(fields[1]), "*")
+
+		actual, err := sha256File(path.Join(path.Dir(sumFile), name))
+		if err != nil {
+			return fmt.Errorf("failed to verify %v: %w", name, err)
+		}
+		if actual != expected {
+			return fmt.Errorf("checksum mismatch of %v", name)
+		}
+	}
+	return scanner.Err()
+}
+
+func sha256File(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// validate sets TimeStampedObject.Invalid of the objects failing one of
+// checks
+func validate(objects []TimeStampedObject, checks []ValidityCheck) {
+	for i := range objects {
+		object := &objects[i]
+		for _, check := range checks {
+			if err := check.Check(*object); err != nil {
+				object.Invalid = err.Error()
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package retention
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestRequiredGlobCheck(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	writeTestFiles(rootDir, map[string]string{
+		"valid/backup.tar.gz":       "backup",
+		"invalid/backup.tar":        "backup",
+		"db[1]-2000-01-01/a.tar.gz": "backup",
+		"db*-2000-01-01/a.tar":      "backup",
+		"db*-2000-01-01-x/b.tar.gz": "backup",
+	}, t)
+
+	testCases := []struct {
+		object        TimeStampedObject
+		expectedValid bool
+	}{
+		{TimeStampedObject{Path: path.Join(rootDir, "valid"), IsDir: true}, true},
+		{TimeStampedObject{Path: path.Join(rootDir, "invalid"), IsDir: true}, false},
+		{TimeStampedObject{Path: path.Join(rootDir, "valid", "backup.tar.gz")}, true},
+		// Glob characters in the name of the directory are not part of the glob
+		{TimeStampedObject{Path: path.Join(rootDir, "db[1]-2000-01-01"), IsDir: true}, true},
+		{TimeStampedObject{Path: path.Join(rootDir, "db*-2000-01-01"), IsDir: true}, false},
+	}
+
+	for _, testCase := range testCases {
+		// Act
+		err := RequiredGlobCheck{Glob: "*.tar.gz"}.Check(testCase.object)
+
+		// Assert
+		if actual := err == nil; actual != testCase.expectedValid {
+			t.Errorf("%v: Expected valid %v, got %v", testCase.object.Path, testCase.expectedValid, err)
+		}
+	}
+}
+
+func TestCompletionMarkerCheck(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	writeTestFiles(rootDir, map[string]string{
+		"complete/backup.tar.gz":   "backup",
+		"complete/.complete":       "",
+		"incomplete/backup.tar.gz": "back",
+		"db.sql.gz":                "backup",
+		"db.sql.gz.complete":       "",
+		"db-partial.sql.gz":        "back",
+	}, t)
+
+	testCases := []struct {
+		object        TimeStampedObject
+		expectedValid bool
+	}{
+		{TimeStampedObject{Path: path.Join(rootDir, "complete"), IsDir: true}, true},
+		{TimeStampedObject{Path: path.Join(rootDir, "incomplete"), IsDir: true}, false},
+		{TimeStampedObject{Path: path.Join(rootDir, "db.sql.gz")}, true},
+		{TimeStampedObject{Path: path.Join(rootDir, "db-partial.sql.gz")}, false},
+	}
+
+	for _, testCase := range testCases {
+		// Act
+		err := CompletionMarkerCheck{Marker: ".complete"}.Check(testCase.object)
+
+		// Assert
+		if actual := err == nil; actual != testCase.expectedValid {
+			t.Errorf("%v: Expected valid %v, got %v", testCase.object.Path, testCase.expectedValid, err)
+		}
+	}
+}
+
+func TestSHA256SumCheck(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	sum := sha256.Sum256([]byte("backup"))
+	checksum := hex.EncodeToString(sum[:])
+	writeTestFiles(rootDir, map[string]string{
+		"valid/backup.tar.gz":             "backup",
+		"valid/backup.tar.gz.sha256sum":   checksum + "  backup.tar.gz\n",
+		"binary/backup.tar.gz":            "backup",
+		"binary/backup.tar.gz.sha256sum":  checksum + " *backup.tar.gz\n",
+		"corrupt/backup.tar.gz":           "backu",
+		"corrupt/backup.tar.gz.sha256sum": checksum + "  backup.tar.gz\n",
+		"missing/backup.tar.gz.sha256sum": checksum + "  backup.tar.gz\n",
+		"garbage/backup.tar.gz":           "backup",
+		"garbage/backup.tar.gz.sha256sum": "backup.tar.gz.sha256sum",
+		"unchecked/backup.tar.gz":         "backup",
+		"db.sql.gz":                       "backup",
+		"db.sql.gz.sha256sum":             checksum + "  db.sql.gz\n",
+		"db-corrupt.sql.gz":               "backu",
+		"db-corrupt.sql.gz.sha256sum":     checksum + "  db-corrupt.sql.gz\n",
+		"corrupt[1]/a.tar.gz":             "backu",
+		"corrupt[1]/a.tar.gz.sha256sum":   checksum + "  a.tar.gz\n",
+	}, t)
+
+	testCases := []struct {
+		object        TimeStampedObject
+		expectedValid bool
+	}{
+		{TimeStampedObject{Path: path.Join(rootDir, "valid"), IsDir: true}, true},
+		{TimeStampedObject{Path: path.Join(rootDir, "binary"), IsDir: true}, true},
+		{TimeStampedObject{Path: path.Join(rootDir, "corrupt"), IsDir: true}, false},
+		{TimeStampedObject{Path: path.Join(rootDir, "missing"), IsDir: true}, false},
+		{TimeStampedObject{Path: path.Join(rootDir, "garbage"), IsDir: true}, false},
+		{TimeStampedObject{Path: path.Join(rootDir, "unchecked"), IsDir: true}, true},
+		{TimeStampedObject{Path: path.Join(rootDir, "db.sql.gz")}, true},
+		{TimeStampedObject{Path: path.Join(rootDir, "db-corrupt.sql.gz")}, false},
+		// Glob characters in the name of the directory are not part of the glob
+		{TimeStampedObject{Path: path.Join(rootDir, "corrupt[1]"), IsDir: true}, false},
+	}
+
+	for _, testCase := range testCases {
+		// Act
+		err := SHA256SumCheck{}.Check(testCase.object)
+
+		// Assert
+		if actual := err == nil; actual != testCase.expectedValid {
+			t.Errorf("%v: Expected valid %v, got %v", testCase.object.Path, testCase.expectedValid, err)
+		}
+	}
+}
+
+func TestGetObjectsValidityChecks(t *testing.T) {
+	rootDir := t.TempDir()
+
+	// Arrange
+	writeTestFiles(rootDir, map[string]string{
+		"2000-01-01/backup.tar.gz": "backup",
+		"2000-01-02/backup.tar":    "backup",
+	}, t)
+
+	// Act
+	traverser := FileSystemTraverser{Pattern: PatternISO8601DateOnly, ValidityChecks: []ValidityCheck{RequiredGlobCheck{Glob: "*.tar.gz"}}}
+	objects, err := traverser.GetObjects(rootDir)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to get objects for path %s: %v", rootDir, err)
+	}
+
+	objectsMap := toObjectsMap(objects)
+	if actual := objectsMap[path.Join(rootDir, "2000-01-01")].Invalid; actual != "" {
+		t.Errorf("Expected 2000-01-01 to be valid, got %v", actual)
+	}
+	if expected, actual := "no entry matching *.tar.gz", objectsMap[path.Join(rootDir, "2000-01-02")].Invalid; actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+// writeTestFiles creates the files (and their directories) relative to
+// rootDir with the given content
+func writeTestFiles(rootDir string, files map[string]string, t *testing.T) {
+	for name, content := range files {
+		filePath := path.Join(rootDir, name)
+		if err := os.MkdirAll(path.Dir(filePath), 0755); err != nil {
+			t.Fatalf("Failed to create directory of %s: %v", name, err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file %s: %v", name, err)
+		}
+	}
+}
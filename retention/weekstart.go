@@ -0,0 +1,62 @@
+package retention
+
+import (
+	"fmt"
+	"time"
+)
+
+// WeekStart is the first day of a week used by the weekly rules. The zero
+// value is Monday, the first day of ISO 8601 weeks
+type WeekStart int
+
+const (
+	WeekStartMonday WeekStart = iota
+	WeekStartTuesday
+	WeekStartWednesday
+	WeekStartThursday
+	WeekStartFriday
+	WeekStartSaturday
+	WeekStartSunday
+)
+
+var weekStartNames = map[WeekStart]string{
+	WeekStartMonday:    "monday",
+	WeekStartTuesday:   "tuesday",
+	WeekStartWednesday: "wednesday",
+	WeekStartThursday:  "thursday",
+	WeekStartFriday:    "friday",
+	WeekStartSaturday:  "saturday",
+	WeekStartSunday:    "sunday",
+}
+
+// ParseWeekStart parses the lower case English name of a day, e.g. sunday
+func ParseWeekStart(s string) (WeekStart, error) {
+	for weekStart, name := range weekStartNames {
+		if name == s {
+			return weekStart, nil
+		}
+	}
+	return WeekStartMonday, fmt.Errorf("invalid week start '%v': expected a day like monday or sunday", s)
+}
+
+// Weekday returns the corresponding time.Weekday
+func (w WeekStart) Weekday() time.Weekday {
+	return time.Weekday((int(w) + 1) % 7)
+}
+
+func (w WeekStart) String() string {
+	return weekStartNames[w]
+}
+
+func (w WeekStart) MarshalText() ([]byte, error) {
+	return []byte(w.String()), nil
+}
+
+func (w *WeekStart) UnmarshalText(text []byte) error {
+	weekStart, err := ParseWeekStart(string(text))
+	if err != nil {
+		return err
+	}
+	*w = weekStart
+	return nil
+}